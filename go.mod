@@ -1,3 +1,3 @@
 module github.com/anidotnet/openvidu-go-client
 
-go 1.12
+go 1.13
@@ -2,27 +2,15 @@ package main
 
 import (
 	"fmt"
+	"os"
+
 	"github.com/anidotnet/openvidu-go-client/openvidu"
 )
 
 func main() {
-	c1 := &openvidu.Connection{
-		ConnectionId: "abc",
-		CreatedAt:    12346,
-		Role:         openvidu.MODERATOR,
-	}
-	c2 := &openvidu.Connection{
-		ConnectionId: "xyz",
-		CreatedAt:    91635,
-		Role:         openvidu.PUBLISHER,
-	}
-
-	ac := make(map[string]*openvidu.Connection, 2)
-	ac["abc"] = c1
-	ac["xyz"] = c2
+	ov := openvidu.NewOpenVidu(os.Getenv("OPENVIDU_URL"), os.Getenv("OPENVIDU_SECRET"))
 
 	p := &openvidu.SessionProperties{
-		DefaultCustomLayout:    "abcd",
 		DefaultRecordingLayout: openvidu.BEST_FIT,
 		DefaultOutputMode:      openvidu.COMPOSED,
 		RecordingMode:          openvidu.MANUAL,
@@ -30,12 +18,19 @@ func main() {
 		CustomSessionId:        "abcd",
 	}
 
-	s := &openvidu.Session{
-		SessionId:         "13abcd",
-		CreatedAt:         15648793,
-		Recording:         true,
-		ActiveConnections: ac,
-		Properties:        p,
+	s, e := ov.CreateSession1(p)
+	if e != nil {
+		fmt.Printf("Error %v", e)
+		return
+	}
+	if _, e := s.Fetch(); e != nil {
+		fmt.Printf("Error %v", e)
+		return
+	}
+
+	fmt.Printf("Session %s created at %d, recording %v\n", s.SessionId, s.GetCreatedAt(), s.IsRecording())
+	for _, c := range s.GetActiveConnections() {
+		fmt.Printf("Connection %s (%s)\n", c.ConnectionId, c.Role)
 	}
 
 	json, e := s.ToJson()
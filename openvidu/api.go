@@ -0,0 +1,72 @@
+package openvidu
+
+import (
+	"context"
+	"io"
+)
+
+// SessionAPI creates and tracks the sessions of an OpenVidu server. Fake
+// implementations can return sessions built with NewSessionWithClient.
+type SessionAPI interface {
+	CreateSession0Context(ctx context.Context) (*Session, error)
+	CreateSession1Context(ctx context.Context, properties *SessionProperties) (*Session, error)
+	FetchContext(ctx context.Context) (bool, error)
+	FetchChangesContext(ctx context.Context) ([]*Change, error)
+	FetchSessionsContext(ctx context.Context) ([]*Session, error)
+	GetActiveSessions() []*Session
+	GetActiveSession(sessionId string) *Session
+}
+
+// RecordingAPI manages the recordings of an OpenVidu server.
+type RecordingAPI interface {
+	StartRecordingContext(ctx context.Context, sessionId string, properties *RecordingProperties) (*Recording, error)
+	StopRecordingContext(ctx context.Context, recordingId string) (*Recording, error)
+	GetRecordingContext(ctx context.Context, recordingId string) (*Recording, error)
+	ListRecordingContext(ctx context.Context) ([]*Recording, error)
+	DeleteRecordingContext(ctx context.Context, recordingId string) error
+	OpenRecordingRangeContext(ctx context.Context, recordingId string, offset int64, length int64) (io.ReadCloser, error)
+}
+
+// SignalAPI sends signals to the participants of a session.
+type SignalAPI interface {
+	SendSignalContext(ctx context.Context, sessionId string, signalType string, data string, connectionIds ...string) error
+}
+
+// Client is the API implemented by OpenVidu.
+type Client interface {
+	SessionAPI
+	RecordingAPI
+	SignalAPI
+}
+
+// TokenAPI generates tokens to connect to a session.
+type TokenAPI interface {
+	GenerateTokenContext(ctx context.Context, to *TokenOptions) (*Token, error)
+}
+
+// ConnectionAPI manages and moderates the connections and streams of a
+// session.
+type ConnectionAPI interface {
+	CreateConnectionContext(ctx context.Context, co *ConnectionOptions) (*Connection, error)
+	PublishIPCameraContext(ctx context.Context, rtspUri string, opts *IPCameraOptions) (*Connection, error)
+	GetConnectionContext(ctx context.Context, connectionId string) (*Connection, error)
+	ListConnectionsContext(ctx context.Context) ([]*Connection, error)
+	UpdateConnectionContext(ctx context.Context, connectionId string, co *ConnectionOptions) (*Connection, error)
+	ForceDisconnectByIdContext(ctx context.Context, connectionId string) error
+	ForceUnpublishByIdContext(ctx context.Context, streamId string) error
+	SignalByIdContext(ctx context.Context, signalType string, data string, connectionIds ...string) error
+}
+
+// SessionClient is the API implemented by Session.
+type SessionClient interface {
+	TokenAPI
+	ConnectionAPI
+	FetchContext(ctx context.Context) (bool, error)
+	FetchChangesContext(ctx context.Context) ([]*Change, error)
+	CloseContext(ctx context.Context) error
+}
+
+var (
+	_ Client        = (*OpenVidu)(nil)
+	_ SessionClient = (*Session)(nil)
+)
@@ -0,0 +1,186 @@
+package openvidu
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"time"
+)
+
+// ErrArchiveVerification is returned when the media read back from a
+// Storage does not match the media archived.
+var ErrArchiveVerification = errors.New("openvidu: archived recording does not match the original")
+
+// ArchiveOptions configure an Archiver.
+type ArchiveOptions struct {
+	// KeyPrefix is prepended to the keys of the archived files, which are
+	// "<recordingId>/<file name>" for the media and
+	// "<recordingId>/recording.json" for the metadata.
+	KeyPrefix string
+
+	// Verify reads the media back from the storage after storing it and
+	// checks its size and SHA-256 checksum.
+	Verify bool
+
+	// DeleteAfterArchive deletes the recording from OpenVidu once archived.
+	DeleteAfterArchive bool
+}
+
+// ArchiveMetadata is the JSON file stored along with an archived recording.
+type ArchiveMetadata struct {
+	Id         string               `json:"id"`
+	SessionId  string               `json:"sessionId"`
+	Status     RecordingStatus      `json:"status"`
+	CreatedAt  int64                `json:"createdAt"`
+	Size       int64                `json:"size"`
+	Duration   float64              `json:"duration"`
+	Properties *RecordingProperties `json:"properties,omitempty"`
+	Session    *ArchivedSession     `json:"session,omitempty"`
+	MediaKey   string               `json:"mediaKey"`
+	SHA256     string               `json:"sha256"`
+	ArchivedAt int64                `json:"archivedAt"`
+}
+
+// ArchivedSession holds the details of the recorded session, when the
+// client still knew it at archive time.
+type ArchivedSession struct {
+	CreatedAt  int64              `json:"createdAt"`
+	Properties *SessionProperties `json:"properties,omitempty"`
+}
+
+// Archiver copies READY recordings from OpenVidu to a Storage.
+type Archiver struct {
+	openVidu *OpenVidu
+	storage  Storage
+	opts     ArchiveOptions
+}
+
+// NewArchiver creates an archiver downloading recordings with ov into
+// storage. A nil opts uses the zero ArchiveOptions.
+func NewArchiver(ov *OpenVidu, storage Storage, opts *ArchiveOptions) *Archiver {
+	a := &Archiver{
+		openVidu: ov,
+		storage:  storage,
+	}
+	if opts != nil {
+		a.opts = *opts
+	}
+	return a
+}
+
+// Archive streams the media of a READY recording into the storage, then
+// stores its metadata, so the metadata only exists for complete media. The
+// recording should be fresh, e.g. from GetRecording or WaitForRecording.
+func (a *Archiver) Archive(r *Recording) (*ArchiveMetadata, error) {
+	return a.ArchiveContext(context.Background(), r)
+}
+
+func (a *Archiver) ArchiveContext(ctx context.Context, r *Recording) (*ArchiveMetadata, error) {
+	if r.Status != READY || len(r.Url) == 0 {
+		return nil, fmt.Errorf("%w: recording %s is %s", ErrRecordingNotReady, r.Id, r.Status)
+	}
+	u, err := url.Parse(r.Url)
+	if err != nil {
+		return nil, err
+	}
+	mediaKey := a.key(r, path.Base(u.Path))
+
+	rc, err := a.openVidu.openRecording(ctx, r, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	size := &countingWriter{}
+	err = a.storage.Put(ctx, mediaKey, io.TeeReader(rc, io.MultiWriter(h, size)))
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	if a.opts.Verify {
+		if err := a.verify(ctx, mediaKey, size.n, sum); err != nil {
+			return nil, err
+		}
+	}
+
+	metadata := a.metadata(r, mediaKey, size.n, sum)
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := a.storage.Put(ctx, a.key(r, "recording.json"), bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+
+	if a.opts.DeleteAfterArchive {
+		if err := a.openVidu.DeleteRecordingContext(ctx, r.Id); err != nil && !errors.Is(err, ErrRecordingNotFound) {
+			return metadata, err
+		}
+	}
+	return metadata, nil
+}
+
+func (a *Archiver) verify(ctx context.Context, key string, size int64, sum string) error {
+	rc, err := a.storage.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, rc)
+	if err != nil {
+		return err
+	}
+	if n != size || hex.EncodeToString(h.Sum(nil)) != sum {
+		return fmt.Errorf("%w: %s", ErrArchiveVerification, key)
+	}
+	return nil
+}
+
+func (a *Archiver) key(r *Recording, name string) string {
+	return a.opts.KeyPrefix + r.Id + "/" + name
+}
+
+func (a *Archiver) metadata(r *Recording, mediaKey string, size int64, sum string) *ArchiveMetadata {
+	m := &ArchiveMetadata{
+		Id:         r.Id,
+		SessionId:  r.SessionId,
+		Status:     r.Status,
+		CreatedAt:  r.CreatedAt,
+		Size:       size,
+		Duration:   r.Duration,
+		MediaKey:   mediaKey,
+		SHA256:     sum,
+		ArchivedAt: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	if rp := r.RecordingProperties; rp != nil {
+		p := *rp
+		m.Properties = &p
+	}
+
+	if s := a.openVidu.GetActiveSession(r.SessionId); s != nil {
+		m.Session = &ArchivedSession{
+			CreatedAt:  s.GetCreatedAt(),
+			Properties: s.GetProperties(),
+		}
+	}
+	return m
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,84 @@
+package openvidu_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+func TestArchive(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	rp := (&openvidu.RecordingProperties{
+		Name:       "meeting",
+		OutputMode: openvidu.COMPOSED,
+		HasAudio:   true,
+		HasVideo:   true,
+		FrameRate:  30,
+		ShmSize:    openvidu.MIN_SHM_SIZE,
+		MediaNode:  "media_1",
+	}).Build()
+	rec, err := ov.StartRecording(session.SessionId, rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ov.StopRecording(rec.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.SetRecordingStatus(rec.Id, openvidu.READY); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err = ov.GetRecording(rec.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	storage := openvidu.NewFileStorage(dir)
+	archiver := openvidu.NewArchiver(ov, storage, &openvidu.ArchiveOptions{KeyPrefix: "rec/", Verify: true})
+	m, err := archiver.Archive(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := storage.Open(context.Background(), "rec/"+rec.Id+"/recording.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	var stored openvidu.ArchiveMetadata
+	if err := json.NewDecoder(rc).Decode(&stored); err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.Properties == nil || *stored.Properties != *rec.RecordingProperties {
+		t.Errorf("properties = %+v, want %+v", stored.Properties, rec.RecordingProperties)
+	}
+	if stored.Properties != nil && (stored.Properties.FrameRate != 30 || stored.Properties.MediaNode != "media_1") {
+		t.Errorf("properties = %+v, want frameRate and mediaNode", stored.Properties)
+	}
+	if stored.Session == nil || stored.Session.Properties == nil || stored.Session.Properties.MediaMode != openvidu.ROUTED {
+		t.Errorf("session = %+v", stored.Session)
+	}
+	if stored.SHA256 != m.SHA256 || stored.Size != rec.Size {
+		t.Errorf("stored %s/%d, archived %s, recording size %d", stored.SHA256, stored.Size, m.SHA256, rec.Size)
+	}
+}
@@ -0,0 +1,266 @@
+package openvidu
+
+import (
+	"sort"
+	"sync"
+)
+
+type ChangeType string
+
+const (
+	SESSION_ADDED              ChangeType = "sessionAdded"
+	SESSION_REMOVED            ChangeType = "sessionRemoved"
+	SESSION_PROPERTIES_CHANGED ChangeType = "sessionPropertiesChanged"
+	RECORDING_STARTED          ChangeType = "recordingStarted"
+	RECORDING_STOPPED          ChangeType = "recordingStopped"
+	CONNECTION_JOINED          ChangeType = "connectionJoined"
+	CONNECTION_LEFT            ChangeType = "connectionLeft"
+	CONNECTION_CHANGED         ChangeType = "connectionChanged"
+	PUBLISHER_STARTED          ChangeType = "publisherStarted"
+	PUBLISHER_STOPPED          ChangeType = "publisherStopped"
+	MEDIA_OPTIONS_CHANGED      ChangeType = "mediaOptionsChanged"
+	SUBSCRIBER_ADDED           ChangeType = "subscriberAdded"
+	SUBSCRIBER_REMOVED         ChangeType = "subscriberRemoved"
+)
+
+// Change describes a single difference between two states of a session.
+//
+// ConnectionId is set for connection, publisher and subscriber changes and
+// StreamId for publisher and subscriber changes. Connection and Publisher
+// hold the new state, and Previous and PreviousPublisher the old one, where
+// it applies.
+type Change struct {
+	Type              ChangeType
+	SessionId         string
+	ConnectionId      string
+	StreamId          string
+	Connection        *Connection
+	Previous          *Connection
+	Publisher         *Publisher
+	PreviousPublisher *Publisher
+}
+
+// sessionState is a copy of the mutable state of a session, used to compute
+// the changes made by an update.
+type sessionState struct {
+	recording   bool
+	properties  SessionProperties
+	connections map[string]*Connection
+}
+
+// state must be called with s.mu held.
+func (s *Session) state() *sessionState {
+	st := &sessionState{
+		recording:   s.recording,
+		connections: make(map[string]*Connection, len(s.activeConnections)),
+	}
+	if s.properties != nil {
+		st.properties = *s.properties
+	}
+	for id, c := range s.activeConnections {
+		st.connections[id] = c.clone()
+	}
+	return st
+}
+
+// update runs fn with s.mu held and returns the changes it made.
+func (s *Session) update(fn func()) []*Change {
+	s.mu.Lock()
+	before := s.state()
+	fn()
+	after := s.state()
+	s.mu.Unlock()
+	return diffSessionState(s.SessionId, before, after)
+}
+
+func (s *Session) snapshotState() *sessionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state()
+}
+
+// diffSessionState returns the changes turning before into after. A nil
+// state stands for a session that does not exist.
+func diffSessionState(sessionId string, before *sessionState, after *sessionState) []*Change {
+	var changes []*Change
+	add := func(c *Change) {
+		c.SessionId = sessionId
+		changes = append(changes, c)
+	}
+
+	if before == nil && after == nil {
+		return nil
+	}
+	if before == nil {
+		add(&Change{Type: SESSION_ADDED})
+		before = &sessionState{properties: after.properties}
+	}
+	removed := after == nil
+	if removed {
+		after = &sessionState{recording: before.recording, properties: before.properties}
+	}
+
+	if before.properties != after.properties {
+		add(&Change{Type: SESSION_PROPERTIES_CHANGED})
+	}
+	if !before.recording && after.recording {
+		add(&Change{Type: RECORDING_STARTED})
+	} else if before.recording && !after.recording {
+		add(&Change{Type: RECORDING_STOPPED})
+	}
+
+	for _, id := range connectionIds(before.connections, after.connections) {
+		bc := before.connections[id]
+		ac := after.connections[id]
+		switch {
+		case bc == nil:
+			add(&Change{Type: CONNECTION_JOINED, ConnectionId: id, Connection: ac})
+			for _, c := range diffConnection(&Connection{}, ac) {
+				add(c)
+			}
+		case ac == nil:
+			for _, c := range diffConnection(bc, &Connection{}) {
+				add(c)
+			}
+			add(&Change{Type: CONNECTION_LEFT, ConnectionId: id, Previous: bc})
+		default:
+			if bc.Role != ac.Role || bc.Status != ac.Status || bc.Record != ac.Record ||
+				bc.ServerData != ac.ServerData || bc.ClientData != ac.ClientData ||
+				bc.Token != ac.Token || bc.Location != ac.Location || bc.Platform != ac.Platform {
+				add(&Change{Type: CONNECTION_CHANGED, ConnectionId: id, Connection: ac, Previous: bc})
+			}
+			for _, c := range diffConnection(bc, ac) {
+				add(c)
+			}
+		}
+	}
+
+	if removed {
+		add(&Change{Type: SESSION_REMOVED})
+	}
+	return changes
+}
+
+// diffConnection returns the publisher and subscriber changes between two
+// states of a connection. The connection id is taken from whichever state
+// has one.
+func diffConnection(before *Connection, after *Connection) []*Change {
+	connectionId := after.ConnectionId
+	if len(connectionId) == 0 {
+		connectionId = before.ConnectionId
+	}
+
+	var changes []*Change
+	for _, id := range publisherIds(before.Publishers, after.Publishers) {
+		bp := before.Publishers[id]
+		ap := after.Publishers[id]
+		c := &Change{ConnectionId: connectionId, StreamId: id, Publisher: ap, PreviousPublisher: bp}
+		switch {
+		case bp == nil:
+			c.Type = PUBLISHER_STARTED
+		case ap == nil:
+			c.Type = PUBLISHER_STOPPED
+		case *bp != *ap:
+			c.Type = MEDIA_OPTIONS_CHANGED
+		default:
+			continue
+		}
+		changes = append(changes, c)
+	}
+
+	for _, streamId := range after.Subscribers {
+		if !contains(before.Subscribers, streamId) {
+			changes = append(changes, &Change{Type: SUBSCRIBER_ADDED, ConnectionId: connectionId, StreamId: streamId})
+		}
+	}
+	for _, streamId := range before.Subscribers {
+		if !contains(after.Subscribers, streamId) {
+			changes = append(changes, &Change{Type: SUBSCRIBER_REMOVED, ConnectionId: connectionId, StreamId: streamId})
+		}
+	}
+	return changes
+}
+
+func connectionIds(a map[string]*Connection, b map[string]*Connection) []string {
+	var ids []string
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if a[id] == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func publisherIds(a map[string]*Publisher, b map[string]*Publisher) []string {
+	var ids []string
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if a[id] == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+type changeSubscribers struct {
+	mu     sync.RWMutex
+	nextId int
+	fns    map[int]func(*Change)
+}
+
+// Subscribe registers fn to be called with every change the client applies
+// to its active sessions, whether from Fetch, webhook events or its own
+// calls. fn is called synchronously, in the order changes happen, and must
+// not block. The returned function cancels the subscription.
+func (o *OpenVidu) Subscribe(fn func(*Change)) func() {
+	cs := &o.changeSubscribers
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.fns == nil {
+		cs.fns = make(map[int]func(*Change))
+	}
+	id := cs.nextId
+	cs.nextId++
+	cs.fns[id] = fn
+
+	return func() {
+		cs.mu.Lock()
+		delete(cs.fns, id)
+		cs.mu.Unlock()
+	}
+}
+
+// publishChanges must not be called while holding o.mu or a session lock,
+// as subscribers may call back into the client.
+func (o *OpenVidu) publishChanges(changes []*Change) {
+	if o == nil || len(changes) == 0 {
+		return
+	}
+
+	cs := &o.changeSubscribers
+	cs.mu.RLock()
+	ids := make([]int, 0, len(cs.fns))
+	for id := range cs.fns {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	fns := make([]func(*Change), 0, len(ids))
+	for _, id := range ids {
+		fns = append(fns, cs.fns[id])
+	}
+	cs.mu.RUnlock()
+
+	for _, c := range changes {
+		for _, fn := range fns {
+			fn(c)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package openvidu_test
+
+import (
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+func TestCreateExistingSessionKeepsState(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+
+	var added int
+	ov.Subscribe(func(c *openvidu.Change) {
+		if c.Type == openvidu.SESSION_ADDED {
+			added++
+		}
+	})
+
+	sp := &openvidu.SessionProperties{
+		MediaMode:              openvidu.ROUTED,
+		RecordingMode:          openvidu.MANUAL,
+		DefaultOutputMode:      openvidu.COMPOSED,
+		DefaultRecordingLayout: openvidu.BEST_FIT,
+		CustomSessionId:        "custom",
+	}
+	first, err := ov.CreateSession1(sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.JoinSession("custom", openvidu.PUBLISHER, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.Fetch(); err != nil {
+		t.Fatal(err)
+	}
+	createdAt := first.GetCreatedAt()
+
+	second, err := ov.CreateSession1(sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second != first {
+		t.Error("the cached session was replaced")
+	}
+	if n := len(second.GetActiveConnections()); n != 1 {
+		t.Errorf("connections = %d, want 1", n)
+	}
+	if second.GetCreatedAt() != createdAt {
+		t.Errorf("CreatedAt = %d, want %d", second.GetCreatedAt(), createdAt)
+	}
+	if added != 1 {
+		t.Errorf("SESSION_ADDED published %d times, want 1", added)
+	}
+}
+
+func TestFetchChanges(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	connectionId, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamId, err := srv.Publish(session.SessionId, connectionId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := session.FetchChanges()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []openvidu.ChangeType{openvidu.CONNECTION_JOINED, openvidu.PUBLISHER_STARTED}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changeTypes(changes), want)
+	}
+	for i, c := range changes {
+		if c.Type != want[i] {
+			t.Fatalf("changes = %v, want %v", changeTypes(changes), want)
+		}
+	}
+	if changes[1].StreamId != streamId || changes[1].ConnectionId != connectionId {
+		t.Errorf("publisher change = %+v", changes[1])
+	}
+
+	if changes, err := session.FetchChanges(); err != nil || len(changes) != 0 {
+		t.Errorf("changes = %v, %v, want none", changeTypes(changes), err)
+	}
+}
+
+func changeTypes(changes []*openvidu.Change) []openvidu.ChangeType {
+	var types []openvidu.ChangeType
+	for _, c := range changes {
+		types = append(types, c.Type)
+	}
+	return types
+}
@@ -0,0 +1,12 @@
+package openvidu
+
+// ConnectionOptions configure a connection created with
+// Session.CreateConnection. Only Role and Record can be changed with
+// Session.UpdateConnection.
+type ConnectionOptions struct {
+	Type           ConnectionType
+	Data           string
+	Role           OpenViduRole
+	Record         *bool
+	KurentoOptions *KurentoOptions
+}
@@ -0,0 +1,105 @@
+package openvidu_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+// TestConcurrentUse is meant to be run with -race.
+func TestConcurrentUse(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		connectionId, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.Publish(session.SessionId, connectionId, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ov.Fetch(); err != nil {
+		t.Fatal(err)
+	}
+
+	const iterations = 50
+	var wg sync.WaitGroup
+	run := func(name string, fn func(i int) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if err := fn(i); err != nil {
+					t.Errorf("%s: %v", name, err)
+					return
+				}
+			}
+		}()
+	}
+
+	run("Fetch", func(int) error {
+		_, err := ov.Fetch()
+		return err
+	})
+	run("Session.Fetch", func(int) error {
+		_, err := session.Fetch()
+		return err
+	})
+	run("ListConnections", func(int) error {
+		_, err := session.ListConnections()
+		return err
+	})
+	run("ApplyWebhookEvent", func(i int) error {
+		header := openvidu.EventHeader{SessionId: session.SessionId}
+		participantId := fmt.Sprintf("webhook_%d", i)
+		ov.ApplyWebhookEvent(&openvidu.ParticipantJoinedEvent{EventHeader: header, ParticipantId: participantId})
+		ov.ApplyWebhookEvent(&openvidu.RecordingStatusChangedEvent{EventHeader: header, Status: openvidu.STARTED})
+		ov.ApplyWebhookEvent(&openvidu.ParticipantLeftEvent{EventHeader: header, ParticipantId: participantId})
+		ov.ApplyWebhookEvent(&openvidu.RecordingStatusChangedEvent{EventHeader: header, Status: openvidu.STOPPED})
+		return nil
+	})
+	run("accessors", func(int) error {
+		session.IsRecording()
+		session.GetCreatedAt()
+		session.GetProperties()
+		session.GetActiveConnection("webhook_0")
+		for _, s := range ov.GetActiveSessions() {
+			for _, c := range s.GetActiveConnections() {
+				c.GetPublishers()
+				c.Subscribers = append(c.Subscribers, "modified")
+			}
+			if _, err := s.ToJson(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	run("Subscribe", func(int) error {
+		cancel := ov.Subscribe(func(c *openvidu.Change) {
+			if c.Connection != nil {
+				c.Connection.GetPublishers()
+			}
+		})
+		cancel()
+		return nil
+	})
+	wg.Wait()
+
+	for _, c := range session.GetActiveConnections() {
+		for _, streamId := range c.Subscribers {
+			if streamId == "modified" {
+				t.Fatal("a snapshot shares state with the session")
+			}
+		}
+	}
+}
@@ -1,22 +1,119 @@
 package openvidu
 
+import "strings"
+
 type Connection struct {
-	ConnectionId string
-	CreatedAt    int64
-	Role         OpenViduRole
-	Token        string
-	Location     string
-	Platform     string
-	ServerData   string
-	ClientData   string
-	Publishers   map[string]*Publisher
-	Subscribers  []string
+	ConnectionId   string
+	Type           ConnectionType
+	Status         ConnectionStatus
+	CreatedAt      int64
+	ActiveAt       int64
+	Role           OpenViduRole
+	Record         bool
+	Token          string
+	Location       string
+	Platform       string
+	ServerData     string
+	ClientData     string
+	KurentoOptions *KurentoOptions
+	Publishers     map[string]*Publisher
+	Subscribers    []string
+
+	// IP camera connections only.
+	RtspUri                 string
+	AdaptativeBitrate       bool
+	OnlyPlayWithSubscribers bool
+	NetworkCache            int
+}
+
+func newConnection(con *connectionContent) *Connection {
+	pubMap := make(map[string]*Publisher, 0)
+	for _, publisher := range con.Publishers {
+		p := &Publisher{
+			CreatedAt: publisher.CreatedAt,
+			StreamId:  publisher.StreamID,
+		}
+		if mediaOptions := publisher.MediaOptions; mediaOptions != nil {
+			p.AudioActive = mediaOptions.AudioActive
+			p.FrameRate = mediaOptions.FrameRate
+			p.HasAudio = mediaOptions.HasAudio
+			p.HasVideo = mediaOptions.HasVideo
+			p.TypeOfVideo = mediaOptions.TypeOfVideo
+			p.VideoActive = mediaOptions.VideoActive
+			p.VideoDimensions = mediaOptions.VideoDimensions
+		}
+		pubMap[p.StreamId] = p
+	}
+
+	subscribers := make([]string, 0)
+	for _, subscriber := range con.Subscribers {
+		subscribers = append(subscribers, subscriber.StreamID)
+	}
+
+	connectionId := con.ConnectionId
+	if len(connectionId) == 0 {
+		connectionId = con.Id
+	}
+	status := con.Status
+	if len(status) == 0 {
+		// Servers not reporting the status only list active connections.
+		status = ACTIVE
+	}
+
+	return &Connection{
+		ConnectionId:   connectionId,
+		Type:           con.Type,
+		Status:         status,
+		CreatedAt:      con.CreatedAt,
+		ActiveAt:       con.ActiveAt,
+		Subscribers:    subscribers,
+		ServerData:     con.ServerData,
+		ClientData:     con.ClientData,
+		Token:          con.Token,
+		Role:           con.Role,
+		Record:         con.Record,
+		KurentoOptions: con.KurentoOptions,
+		Publishers:     pubMap,
+		Location:       con.Location,
+		Platform:       con.Platform,
+
+		RtspUri:                 con.RtspUri,
+		AdaptativeBitrate:       con.AdaptativeBitrate,
+		OnlyPlayWithSubscribers: con.OnlyPlayWithSubscribers,
+		NetworkCache:            con.NetworkCache,
+	}
 }
 
 func (c *Connection) GetPublishers() []*Publisher {
 	v := make([]*Publisher, 0)
-	for  _, value := range c.Publishers {
+	for _, value := range c.Publishers {
 		v = append(v, value)
 	}
 	return v
 }
+
+func (c *Connection) clone() *Connection {
+	cc := *c
+	cc.Publishers = make(map[string]*Publisher, len(c.Publishers))
+	for k, p := range c.Publishers {
+		pc := *p
+		cc.Publishers[k] = &pc
+	}
+	if c.Subscribers != nil {
+		cc.Subscribers = append([]string(nil), c.Subscribers...)
+	}
+	if c.KurentoOptions != nil {
+		cc.KurentoOptions = c.KurentoOptions.clone()
+	}
+	return &cc
+}
+
+func (c *Connection) removeSubscriber(streamId string) {
+	var newSubscribers []string
+	for _, subscriber := range c.Subscribers {
+		if strings.Compare(streamId, subscriber) != 0 {
+			newSubscribers = append(newSubscribers, subscriber)
+		}
+	}
+	c.Subscribers = newSubscribers
+}
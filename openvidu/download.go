@@ -0,0 +1,228 @@
+package openvidu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OpenRecording opens the media file of a READY recording: an MP4 or WEBM
+// file for COMPOSED recordings, or a ZIP file for INDIVIDUAL ones. The file
+// is fetched from the host of the client with its credentials and
+// transport, whatever the host in Recording.Url, so the secret is never
+// sent elsewhere.
+//
+// When the server reports the size of the recording, reading the returned
+// reader fails with ErrRecordingSizeMismatch if the data received does not
+// match it. The caller must close the reader. The timeout of the client does
+// not apply to reading the media, which is only bounded by the context of
+// OpenRecordingContext.
+func (o *OpenVidu) OpenRecording(recordingId string) (io.ReadCloser, error) {
+	return o.OpenRecordingContext(context.Background(), recordingId)
+}
+
+func (o *OpenVidu) OpenRecordingContext(ctx context.Context, recordingId string) (io.ReadCloser, error) {
+	return o.OpenRecordingRangeContext(ctx, recordingId, 0, -1)
+}
+
+// OpenRecordingRange is like OpenRecording, but only reads length bytes
+// starting at offset, e.g. to resume an interrupted download. A negative
+// length reads until the end of the file.
+func (o *OpenVidu) OpenRecordingRange(recordingId string, offset int64, length int64) (io.ReadCloser, error) {
+	return o.OpenRecordingRangeContext(context.Background(), recordingId, offset, length)
+}
+
+func (o *OpenVidu) OpenRecordingRangeContext(ctx context.Context, recordingId string, offset int64, length int64) (io.ReadCloser, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("openvidu: offset must not be negative, got %d", offset)
+	}
+
+	r, err := o.GetRecordingContext(ctx, recordingId)
+	if err != nil {
+		return nil, err
+	}
+	return o.openRecording(ctx, r, offset, length)
+}
+
+func (o *OpenVidu) openRecording(ctx context.Context, r *Recording, offset int64, length int64) (io.ReadCloser, error) {
+	recordingId := r.Id
+	if r.Status != READY || len(r.Url) == 0 {
+		return nil, fmt.Errorf("%w: recording %s is %s", ErrRecordingNotReady, recordingId, r.Status)
+	}
+
+	// expected is the number of bytes to read, or -1 if unknown.
+	expected := length
+	if r.Size > 0 {
+		if offset > r.Size {
+			return nil, fmt.Errorf("openvidu: offset %d is past the end of recording %s of %d bytes", offset, recordingId, r.Size)
+		}
+		if remaining := r.Size - offset; expected < 0 || expected > remaining {
+			expected = remaining
+		}
+	}
+	if expected == 0 {
+		return ioutil.NopCloser(strings.NewReader("")), nil
+	}
+
+	mediaUrl, err := o.recordingMediaUrl(r.Url)
+	if err != nil {
+		return nil, err
+	}
+	req, err := o.newRequest(ctx, "GET", mediaUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 || expected > 0 {
+		byteRange := "bytes=" + strconv.FormatInt(offset, 10) + "-"
+		if expected > 0 {
+			byteRange += strconv.FormatInt(offset+expected-1, 10)
+		}
+		req.Header.Set("Range", byteRange)
+	}
+
+	response, err := o.do(OP_DOWNLOAD_RECORDING, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader = response.Body
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		if start := contentRangeStart(response.Header.Get("Content-Range")); start != offset {
+			response.Body.Close()
+			return nil, fmt.Errorf("openvidu: server returned range starting at %d, want %d", start, offset)
+		}
+	case http.StatusOK:
+		// The server ignored the range, so skip to offset.
+		if offset > 0 {
+			if _, err := io.CopyN(ioutil.Discard, response.Body, offset); err != nil {
+				response.Body.Close()
+				return nil, err
+			}
+		}
+	default:
+		defer response.Body.Close()
+		return nil, newAPIError(OP_DOWNLOAD_RECORDING, response)
+	}
+	if expected > 0 {
+		// Guards against servers sending more than asked for.
+		body = io.LimitReader(body, expected+1)
+	}
+
+	return &recordingReader{body: body, closer: response.Body, expected: expected}, nil
+}
+
+// DownloadRecording writes the media file of a READY recording to w and
+// returns the number of bytes written. See OpenRecording.
+func (o *OpenVidu) DownloadRecording(recordingId string, w io.Writer) (int64, error) {
+	return o.DownloadRecordingContext(context.Background(), recordingId, w)
+}
+
+func (o *OpenVidu) DownloadRecordingContext(ctx context.Context, recordingId string, w io.Writer) (int64, error) {
+	rc, err := o.OpenRecordingContext(ctx, recordingId)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// DownloadRecordingToFile downloads the media file of a READY recording to
+// path. The file is written to a temporary file in the same directory and
+// renamed to path once complete, so path never holds a partial download.
+func (o *OpenVidu) DownloadRecordingToFile(recordingId string, path string) error {
+	return o.DownloadRecordingToFileContext(context.Background(), recordingId, path)
+}
+
+func (o *OpenVidu) DownloadRecordingToFileContext(ctx context.Context, recordingId string, path string) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := o.DownloadRecordingContext(ctx, recordingId, w)
+		return err
+	})
+}
+
+// recordingMediaUrl returns the url of the media file at recordingUrl on the
+// host of the client.
+func (o *OpenVidu) recordingMediaUrl(recordingUrl string) (string, error) {
+	ref, err := url.Parse(recordingUrl)
+	if err != nil {
+		return "", err
+	}
+	base, err := url.Parse(o.hostName)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(&url.URL{Path: ref.Path, RawQuery: ref.RawQuery}).String(), nil
+}
+
+// contentRangeStart returns the first byte of a "bytes first-last/size"
+// Content-Range header, or -1 if it cannot be parsed.
+func contentRangeStart(contentRange string) int64 {
+	if !strings.HasPrefix(contentRange, "bytes ") {
+		return -1
+	}
+	spec := strings.TrimPrefix(contentRange, "bytes ")
+	i := strings.IndexByte(spec, '-')
+	if i < 0 {
+		return -1
+	}
+	start, err := strconv.ParseInt(spec[:i], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return start
+}
+
+// writeFileAtomic writes path with write through a temporary file renamed
+// to path on success.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.part")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	err = write(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+type recordingReader struct {
+	body     io.Reader
+	closer   io.Closer
+	read     int64
+	expected int64
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.read += int64(n)
+	if r.expected >= 0 {
+		if r.read > r.expected || (err == io.EOF && r.read != r.expected) {
+			return n, fmt.Errorf("%w: read %d bytes, want %d", ErrRecordingSizeMismatch, r.read, r.expected)
+		}
+	}
+	return n, err
+}
+
+func (r *recordingReader) Close() error {
+	return r.closer.Close()
+}
@@ -0,0 +1,64 @@
+package openvidu_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+)
+
+// newSlowMediaServer serves a READY recording rec_1 whose media is written
+// in chunks, one every delay.
+func newSlowMediaServer(chunks [][]byte, delay time.Duration) *httptest.Server {
+	size := 0
+	for _, c := range chunks {
+		size += len(c)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/recordings/rec_1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"rec_1","sessionId":"ses_1","status":"ready","outputMode":"COMPOSED","hasAudio":true,"size":%d,"url":"https://elsewhere/openvidu/recordings/rec_1/rec_1.mp4"}`, size)
+	})
+	mux.HandleFunc("/openvidu/recordings/rec_1/rec_1.mp4", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(size))
+		w.WriteHeader(http.StatusOK)
+		for _, c := range chunks {
+			w.Write(c)
+			w.(http.Flusher).Flush()
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestDownloadSlowRecording(t *testing.T) {
+	chunks := [][]byte{[]byte("slow "), []byte("recorded "), []byte("media")}
+	srv := newSlowMediaServer(chunks, 50*time.Millisecond)
+	defer srv.Close()
+
+	// The timeout of API calls does not cut downloads outlasting it.
+	ov := openvidu.NewOpenVidu(srv.URL, "secret", openvidu.WithTimeout(60*time.Millisecond))
+	var buf bytes.Buffer
+	if _, err := ov.DownloadRecording("rec_1", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := bytes.Join(chunks, nil); !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("downloaded %q, want %q", buf.Bytes(), want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+	if _, err := ov.DownloadRecordingContext(ctx, "rec_1", &buf); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -57,3 +57,20 @@ const (
 	READY    RecordingStatus = "ready"
 	FAILED   RecordingStatus = "failed"
 )
+
+type ConnectionStatus string
+
+const (
+	// The connection has been created but no participant used its token yet
+	PENDING ConnectionStatus = "pending"
+
+	// A participant is connected to the session with the connection
+	ACTIVE ConnectionStatus = "active"
+)
+
+type ConnectionType string
+
+const (
+	WEBRTC ConnectionType = "WEBRTC"
+	IPCAM  ConnectionType = "IPCAM"
+)
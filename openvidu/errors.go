@@ -1,15 +1,217 @@
 package openvidu
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
 
-type openViduError struct {
-	Status int	`json:"status"`
+// Operation names a REST call made by the client. It is carried by APIError
+// so that the same status code can be given an operation-specific meaning.
+type Operation string
+
+const (
+	OP_CREATE_SESSION     Operation = "CreateSession"
+	OP_FETCH_SESSION      Operation = "FetchSession"
+	OP_FETCH_SESSIONS     Operation = "FetchSessions"
+	OP_CLOSE_SESSION      Operation = "CloseSession"
+	OP_GENERATE_TOKEN     Operation = "GenerateToken"
+	OP_FORCE_DISCONNECT   Operation = "ForceDisconnect"
+	OP_FORCE_UNPUBLISH    Operation = "ForceUnpublish"
+	OP_START_RECORDING    Operation = "StartRecording"
+	OP_STOP_RECORDING     Operation = "StopRecording"
+	OP_GET_RECORDING      Operation = "GetRecording"
+	OP_LIST_RECORDINGS    Operation = "ListRecordings"
+	OP_DELETE_RECORDING   Operation = "DeleteRecording"
+	OP_DOWNLOAD_RECORDING Operation = "DownloadRecording"
+	OP_CREATE_CONNECTION  Operation = "CreateConnection"
+	OP_GET_CONNECTION     Operation = "GetConnection"
+	OP_LIST_CONNECTIONS   Operation = "ListConnections"
+	OP_UPDATE_CONNECTION  Operation = "UpdateConnection"
+	OP_SIGNAL             Operation = "Signal"
+	OP_PUBLISH_IP_CAMERA  Operation = "PublishIPCamera"
+)
+
+// Errors matching a response status code regardless of the operation.
+var (
+	ErrBadRequest          = errors.New("openvidu: bad request")
+	ErrUnauthorized        = errors.New("openvidu: unauthorized")
+	ErrNotFound            = errors.New("openvidu: not found")
+	ErrMethodNotAllowed    = errors.New("openvidu: method not allowed")
+	ErrNotAcceptable       = errors.New("openvidu: not acceptable")
+	ErrConflict            = errors.New("openvidu: conflict")
+	ErrUnprocessableEntity = errors.New("openvidu: unprocessable entity")
+	ErrNotImplemented      = errors.New("openvidu: not implemented")
+	ErrServerError         = errors.New("openvidu: server error")
+)
+
+// Errors giving an operation-specific meaning to a response status code.
+var (
+	ErrSessionNotFound          = errors.New("openvidu: session not found")
+	ErrConnectionNotFound       = errors.New("openvidu: connection not found")
+	ErrStreamNotFound           = errors.New("openvidu: stream not found")
+	ErrRecordingNotFound        = errors.New("openvidu: recording not found")
+	ErrRecordingAlreadyStarted  = errors.New("openvidu: session is already being recorded or is not ROUTED")
+	ErrRecordingNotStoppable    = errors.New("openvidu: recording is still starting")
+	ErrRecordingInProgress      = errors.New("openvidu: recording is in progress")
+	ErrNoPublishersForRecording = errors.New("openvidu: session has no connected participants to record")
+	ErrInvalidRecordingLayout   = errors.New("openvidu: resolution or custom layout rejected by the server")
+	ErrRecordingDisabled        = errors.New("openvidu: recording module is disabled")
+	ErrRecordingNotReady        = errors.New("openvidu: recording is not ready for download")
+	ErrRecordingSizeMismatch    = errors.New("openvidu: downloaded size does not match the recording size")
+	ErrRecordingFailed          = errors.New("openvidu: recording failed")
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:          ErrBadRequest,
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusMethodNotAllowed:    ErrMethodNotAllowed,
+	http.StatusNotAcceptable:       ErrNotAcceptable,
+	http.StatusConflict:            ErrConflict,
+	http.StatusUnprocessableEntity: ErrUnprocessableEntity,
+	http.StatusNotImplemented:      ErrNotImplemented,
+}
+
+var operationErrors = map[Operation]map[int]error{
+	OP_FETCH_SESSION: {
+		http.StatusNotFound: ErrSessionNotFound,
+	},
+	OP_CLOSE_SESSION: {
+		http.StatusNotFound: ErrSessionNotFound,
+	},
+	OP_GENERATE_TOKEN: {
+		http.StatusNotFound: ErrSessionNotFound,
+	},
+	OP_FORCE_DISCONNECT: {
+		http.StatusBadRequest: ErrSessionNotFound,
+		http.StatusNotFound:   ErrConnectionNotFound,
+	},
+	OP_FORCE_UNPUBLISH: {
+		http.StatusBadRequest: ErrSessionNotFound,
+		http.StatusNotFound:   ErrStreamNotFound,
+	},
+	OP_CREATE_CONNECTION: {
+		http.StatusNotFound: ErrSessionNotFound,
+	},
+	OP_PUBLISH_IP_CAMERA: {
+		http.StatusNotFound: ErrSessionNotFound,
+	},
+	OP_GET_CONNECTION: {
+		http.StatusBadRequest: ErrSessionNotFound,
+		http.StatusNotFound:   ErrConnectionNotFound,
+	},
+	OP_LIST_CONNECTIONS: {
+		http.StatusNotFound: ErrSessionNotFound,
+	},
+	OP_UPDATE_CONNECTION: {
+		http.StatusNotFound: ErrConnectionNotFound,
+	},
+	OP_SIGNAL: {
+		http.StatusNotFound:      ErrSessionNotFound,
+		http.StatusNotAcceptable: ErrConnectionNotFound,
+	},
+	OP_START_RECORDING: {
+		http.StatusNotFound:            ErrSessionNotFound,
+		http.StatusNotAcceptable:       ErrNoPublishersForRecording,
+		http.StatusConflict:            ErrRecordingAlreadyStarted,
+		http.StatusUnprocessableEntity: ErrInvalidRecordingLayout,
+		http.StatusNotImplemented:      ErrRecordingDisabled,
+	},
+	OP_STOP_RECORDING: {
+		http.StatusNotFound:       ErrRecordingNotFound,
+		http.StatusNotAcceptable:  ErrRecordingNotStoppable,
+		http.StatusNotImplemented: ErrRecordingDisabled,
+	},
+	OP_GET_RECORDING: {
+		http.StatusNotFound:       ErrRecordingNotFound,
+		http.StatusNotImplemented: ErrRecordingDisabled,
+	},
+	OP_LIST_RECORDINGS: {
+		http.StatusNotImplemented: ErrRecordingDisabled,
+	},
+	OP_DELETE_RECORDING: {
+		http.StatusNotFound:       ErrRecordingNotFound,
+		http.StatusConflict:       ErrRecordingInProgress,
+		http.StatusNotImplemented: ErrRecordingDisabled,
+	},
+	OP_DOWNLOAD_RECORDING: {
+		http.StatusNotFound: ErrRecordingNotFound,
+	},
+}
+
+// APIError is returned when the OpenVidu server answers with an unexpected
+// status code. Use errors.Is with the Err* values to find out what went
+// wrong, or errors.As to inspect the response.
+type APIError struct {
+	Operation  Operation
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+	Message    string
+	Err        error
+}
+
+const maxErrorBodySize = 64 * 1024
+
+func newAPIError(op Operation, response *http.Response) *APIError {
+	apiErr := &APIError{
+		Operation:  op,
+		StatusCode: response.StatusCode,
+		Err:        operationErrors[op][response.StatusCode],
+	}
+	if response.Request != nil {
+		apiErr.Method = response.Request.Method
+		apiErr.Path = response.Request.URL.Path
+	}
+
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+	apiErr.Body = string(body)
+
+	res := struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}{}
+	if json.Unmarshal(body, &res) == nil {
+		if len(res.Message) > 0 {
+			apiErr.Message = res.Message
+		} else {
+			apiErr.Message = res.Error
+		}
+	} else {
+		apiErr.Message = strings.TrimSpace(apiErr.Body)
+	}
+	return apiErr
+}
+
+func (err *APIError) Error() string {
+	msg := fmt.Sprintf("openvidu: %s %s: status code %d", err.Method, err.Path, err.StatusCode)
+	if err.Err != nil {
+		msg += ": " + strings.TrimPrefix(err.Err.Error(), "openvidu: ")
+	}
+	if len(err.Message) > 0 {
+		msg += " (" + err.Message + ")"
+	}
+	return msg
 }
 
-func newOpenViduError(status int) *openViduError {
-	return &openViduError{Status: status}
+func (err *APIError) Unwrap() error {
+	return err.Err
 }
 
-func (err *openViduError) Error() string {
-	return fmt.Sprintf("Invalid status code %d recieved from OpenVidu server", err.Status)
+// Is reports whether target is the operation-specific error or the error
+// matching the status code of the response.
+func (err *APIError) Is(target error) bool {
+	if err.Err != nil && err.Err == target {
+		return true
+	}
+	if err.StatusCode >= 500 && target == ErrServerError {
+		return true
+	}
+	return statusErrors[err.StatusCode] == target && target != nil
 }
@@ -0,0 +1,258 @@
+package openvidu
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoRecordingMetadata is returned when opening a ZIP file without the
+// JSON metadata file of an INDIVIDUAL recording.
+var ErrNoRecordingMetadata = errors.New("openvidu: no individual recording metadata found")
+
+// IndividualRecording is the ZIP file of an INDIVIDUAL recording, holding
+// one WEBM file per recorded stream and a JSON file describing them.
+type IndividualRecording struct {
+	Id        string
+	Name      string
+	SessionId string
+	CreatedAt int64
+	Files     []*RecordingFile
+
+	entries map[string]*zip.File
+	closer  io.Closer
+	// tmp is the file downloaded by OpenIndividualRecording, removed on
+	// Close.
+	tmp string
+}
+
+// RecordingFile describes the file of one stream of an INDIVIDUAL
+// recording. ConnectionId and StreamId are those of the Connection and
+// Publisher of the stream. The offsets are the milliseconds between the
+// creation of the recording and the start and end of the stream.
+type RecordingFile struct {
+	Name            string `json:"name"`
+	ConnectionId    string `json:"connectionId"`
+	StreamId        string `json:"streamId"`
+	Size            int64  `json:"size"`
+	ClientData      string `json:"clientData"`
+	ServerData      string `json:"serverData"`
+	HasAudio        bool   `json:"hasAudio"`
+	HasVideo        bool   `json:"hasVideo"`
+	TypeOfVideo     string `json:"typeOfVideo"`
+	StartTimeOffset int64  `json:"startTimeOffset"`
+	EndTimeOffset   int64  `json:"endTimeOffset"`
+}
+
+type individualRecordingJson struct {
+	Id        string           `json:"id"`
+	Name      string           `json:"name"`
+	SessionId string           `json:"sessionId"`
+	CreatedAt int64            `json:"createdAt"`
+	Files     []*RecordingFile `json:"files"`
+}
+
+// OpenIndividualRecordingFile opens the ZIP file of an INDIVIDUAL recording
+// at path. The caller must call Close when done.
+func OpenIndividualRecordingFile(path string) (*IndividualRecording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	ir, err := NewIndividualRecording(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	ir.closer = f
+	return ir, nil
+}
+
+// NewIndividualRecording reads the ZIP file of an INDIVIDUAL recording
+// from r, of the given size.
+func NewIndividualRecording(r io.ReaderAt, size int64) (*IndividualRecording, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	ir := &IndividualRecording{
+		entries: make(map[string]*zip.File, len(zr.File)),
+	}
+	for _, f := range zr.File {
+		ir.entries[f.Name] = f
+	}
+
+	var metadata *individualRecordingJson
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, ".json") || strings.Contains(f.Name, "/") {
+			continue
+		}
+		if metadata, err = readRecordingMetadata(f); err == nil {
+			break
+		}
+	}
+	if metadata == nil {
+		return nil, ErrNoRecordingMetadata
+	}
+
+	ir.Id = metadata.Id
+	ir.Name = metadata.Name
+	ir.SessionId = metadata.SessionId
+	ir.CreatedAt = metadata.CreatedAt
+	ir.Files = metadata.Files
+	return ir, nil
+}
+
+func readRecordingMetadata(f *zip.File) (*individualRecordingJson, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var metadata *individualRecordingJson
+	if err := json.NewDecoder(rc).Decode(&metadata); err != nil {
+		return nil, err
+	}
+	if metadata == nil || metadata.Files == nil {
+		return nil, ErrNoRecordingMetadata
+	}
+	return metadata, nil
+}
+
+// OpenIndividualRecording downloads the ZIP file of a READY INDIVIDUAL
+// recording to a temporary file and opens it. The caller must call Close
+// when done, which removes the temporary file.
+func (o *OpenVidu) OpenIndividualRecording(recordingId string) (*IndividualRecording, error) {
+	return o.OpenIndividualRecordingContext(context.Background(), recordingId)
+}
+
+func (o *OpenVidu) OpenIndividualRecordingContext(ctx context.Context, recordingId string) (*IndividualRecording, error) {
+	r, err := o.GetRecordingContext(ctx, recordingId)
+	if err != nil {
+		return nil, err
+	}
+	if r.OutputMode() != INDIVIDUAL {
+		return nil, fmt.Errorf("openvidu: recording %s has output mode %s, not %s", recordingId, r.OutputMode(), INDIVIDUAL)
+	}
+
+	rc, err := o.openRecording(ctx, r, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	f, err := ioutil.TempFile("", "openvidu-"+recordingId+"-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	tmp := f.Name()
+	_, err = io.Copy(f, rc)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+
+	ir, err := OpenIndividualRecordingFile(tmp)
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	ir.tmp = tmp
+	return ir, nil
+}
+
+// File returns the file of the stream with the given id, or nil if the
+// stream was not recorded.
+func (ir *IndividualRecording) File(streamId string) *RecordingFile {
+	for _, f := range ir.Files {
+		if f.StreamId == streamId {
+			return f
+		}
+	}
+	return nil
+}
+
+// FilesOf returns the files of the streams published by a connection.
+func (ir *IndividualRecording) FilesOf(connectionId string) []*RecordingFile {
+	var files []*RecordingFile
+	for _, f := range ir.Files {
+		if f.ConnectionId == connectionId {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// Open opens the media of a file of the recording. The caller must close
+// the returned reader.
+func (ir *IndividualRecording) Open(f *RecordingFile) (io.ReadCloser, error) {
+	entry := ir.entries[f.Name]
+	if entry == nil {
+		return nil, fmt.Errorf("openvidu: file %s not found in recording %s", f.Name, ir.Id)
+	}
+	return entry.Open()
+}
+
+// Extract writes the media of a file of the recording to path, atomically.
+func (ir *IndividualRecording) Extract(f *RecordingFile, path string) error {
+	rc, err := ir.Open(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := io.Copy(w, rc)
+		return err
+	})
+}
+
+// ExtractAll writes the media of every file of the recording to dir, which
+// is created if needed, under the name of the file, and returns their paths.
+func (ir *IndividualRecording) ExtractAll(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(ir.Files))
+	for _, f := range ir.Files {
+		// The names come from the recording, so keep them inside dir.
+		path := filepath.Join(dir, filepath.Base(f.Name))
+		if err := ir.Extract(f, path); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Close releases the file of the recording.
+func (ir *IndividualRecording) Close() error {
+	var err error
+	if ir.closer != nil {
+		err = ir.closer.Close()
+	}
+	if len(ir.tmp) > 0 {
+		if rmErr := os.Remove(ir.tmp); err == nil {
+			err = rmErr
+		}
+	}
+	return err
+}
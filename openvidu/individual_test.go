@@ -0,0 +1,234 @@
+package openvidu_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+// readyRecording records the session and moves the recording to READY.
+func readyRecording(t *testing.T, srv *openvidutest.Server, ov *openvidu.OpenVidu, sessionId string, outputMode openvidu.OutputMode) *openvidu.Recording {
+	t.Helper()
+	rec, err := ov.StartRecording(sessionId, (&openvidu.RecordingProperties{OutputMode: outputMode, HasAudio: true, HasVideo: true}).Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ov.StopRecording(rec.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.SetRecordingStatus(rec.Id, openvidu.READY); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func TestOpenIndividualRecording(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "bob", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	camera, err := srv.Publish(session.SessionId, alice, &openvidutest.MediaOptions{HasAudio: true, HasVideo: true, TypeOfVideo: "CAMERA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	screen, err := srv.Publish(session.SessionId, alice, &openvidutest.MediaOptions{HasVideo: true, TypeOfVideo: "SCREEN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Publish(session.SessionId, bob, &openvidutest.MediaOptions{HasAudio: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := readyRecording(t, srv, ov, session.SessionId, openvidu.INDIVIDUAL)
+	ir, err := ov.OpenIndividualRecording(rec.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ir.Close()
+
+	if ir.Id != rec.Id || ir.SessionId != session.SessionId || len(ir.Files) != 3 {
+		t.Fatalf("recording = %+v", ir)
+	}
+	if files := ir.FilesOf(alice); len(files) != 2 {
+		t.Errorf("files of alice = %d, want 2", len(files))
+	}
+	if files := ir.FilesOf(bob); len(files) != 1 || files[0].ServerData != "bob" || files[0].HasVideo {
+		t.Errorf("files of bob = %+v", files)
+	}
+	if files := ir.FilesOf("con_unknown"); files != nil {
+		t.Errorf("files of an unknown connection = %+v", files)
+	}
+	if f := ir.File(screen); f == nil || f.ConnectionId != alice || f.TypeOfVideo != "SCREEN" || f.HasAudio {
+		t.Errorf("screen file = %+v", f)
+	}
+	if f := ir.File("str_unknown"); f != nil {
+		t.Errorf("file of an unknown stream = %+v", f)
+	}
+
+	rc, err := ir.Open(ir.File(camera))
+	if err != nil {
+		t.Fatal(err)
+	}
+	media, err := ioutil.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(media)) != ir.File(camera).Size {
+		t.Errorf("media of %d bytes, want %d", len(media), ir.File(camera).Size)
+	}
+
+	dir, err := ioutil.TempDir("", "individual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "camera.webm")
+	if err := ir.Extract(ir.File(camera), path); err != nil {
+		t.Fatal(err)
+	}
+	if extracted, err := ioutil.ReadFile(path); err != nil || !bytes.Equal(extracted, media) {
+		t.Errorf("extracted %d bytes, %v, want %d bytes", len(extracted), err, len(media))
+	}
+
+	paths, err := ir.ExtractAll(filepath.Join(dir, "all"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != len(ir.Files) {
+		t.Fatalf("extracted %v, want %d files", paths, len(ir.Files))
+	}
+	for i, p := range paths {
+		if want := filepath.Join(dir, "all", ir.Files[i].Name); p != want {
+			t.Errorf("path = %s, want %s", p, want)
+		}
+		if fi, err := os.Stat(p); err != nil || fi.Size() != ir.Files[i].Size {
+			t.Errorf("%s: %v, %v", p, fi, err)
+		}
+	}
+}
+
+func TestOpenComposedAsIndividualRecording(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	rec := readyRecording(t, srv, ov, session.SessionId, openvidu.COMPOSED)
+	if ir, err := ov.OpenIndividualRecording(rec.Id); err == nil {
+		ir.Close()
+		t.Fatal("opened a COMPOSED recording as an INDIVIDUAL one")
+	}
+}
+
+func TestIndividualRecordingWithoutMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+	}{
+		{"no json", map[string]string{"str_1.webm": "media"}},
+		{"no files", map[string]string{"str_1.webm": "media", "rec_1.json": `{"id":"rec_1"}`}},
+		{"invalid json", map[string]string{"rec_1.json": `{"id":`}},
+		{"nested json", map[string]string{"dir/rec_1.json": `{"id":"rec_1","files":[]}`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := zipOf(t, tt.entries)
+			if _, err := openvidu.NewIndividualRecording(bytes.NewReader(data), int64(len(data))); !errors.Is(err, openvidu.ErrNoRecordingMetadata) {
+				t.Errorf("err = %v, want ErrNoRecordingMetadata", err)
+			}
+		})
+	}
+
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	rec := readyRecording(t, srv, ov, session.SessionId, openvidu.INDIVIDUAL)
+	if err := srv.SetRecordingMedia(rec.Id, zipOf(t, tests[0].entries)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ov.OpenIndividualRecording(rec.Id); !errors.Is(err, openvidu.ErrNoRecordingMetadata) {
+		t.Errorf("err = %v, want ErrNoRecordingMetadata", err)
+	}
+}
+
+func TestExtractAllKeepsFilesInDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "individual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rec_1.zip")
+	data := zipOf(t, map[string]string{
+		"../evil.webm": "media",
+		"rec_1.json":   `{"id":"rec_1","files":[{"name":"../evil.webm","streamId":"str_1"}]}`,
+	})
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	ir, err := openvidu.OpenIndividualRecordingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ir.Close()
+
+	out := filepath.Join(dir, "out")
+	paths, err := ir.ExtractAll(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0] != filepath.Join(out, "evil.webm") {
+		t.Errorf("paths = %v, want %s", paths, filepath.Join(out, "evil.webm"))
+	}
+}
+
+func zipOf(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,22 @@
+package openvidu
+
+// IPCameraOptions configure an IP camera published with
+// Session.PublishIPCamera. Nil and zero fields are left to the server
+// defaults.
+type IPCameraOptions struct {
+	// Data is the server data of the connection of the camera.
+	Data   string
+	Record *bool
+	// Audio and Video tell whether the camera stream has audio and video.
+	Audio *bool
+	Video *bool
+	// AdaptativeBitrate lets Kurento adapt the bitrate of the stream to the
+	// network conditions of the subscribers, at the cost of transcoding it.
+	AdaptativeBitrate *bool
+	// OnlyPlayWithSubscribers makes Kurento pull the RTSP stream only while
+	// someone is subscribed to it.
+	OnlyPlayWithSubscribers *bool
+	// NetworkCache is the size of the buffer of the RTSP stream, in
+	// milliseconds.
+	NetworkCache int
+}
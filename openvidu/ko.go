@@ -1,9 +1,82 @@
 package openvidu
 
+import "regexp"
+
+// Filters available in every OpenVidu deployment. Filters of custom Kurento
+// modules can be allowed too.
+const (
+	GSTREAMER_FILTER    = "GStreamerFilter"
+	FACE_OVERLAY_FILTER = "FaceOverlayFilter"
+	CHROMA_FILTER       = "ChromaFilter"
+	ZBAR_FILTER         = "ZBarFilter"
+)
+
+// KurentoOptions limit the bandwidth, in kbps, of the streams of a
+// connection and the filters it may apply. Unset bandwidths keep the server
+// defaults, and 0 means unconstrained.
 type KurentoOptions struct {
-	VideoMaxRecvBandwidth *int32
-	VideoMinRecvBandwidth *int32
-	VideoMaxSendBandwidth *int32
-	VideoMinSendBandwidth *int32
-	AllowedFilters        []string
+	VideoMaxRecvBandwidth *int32   `json:"videoMaxRecvBandwidth,omitempty"`
+	VideoMinRecvBandwidth *int32   `json:"videoMinRecvBandwidth,omitempty"`
+	VideoMaxSendBandwidth *int32   `json:"videoMaxSendBandwidth,omitempty"`
+	VideoMinSendBandwidth *int32   `json:"videoMinSendBandwidth,omitempty"`
+	AllowedFilters        []string `json:"allowedFilters,omitempty"`
+}
+
+var filterNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.]*$`)
+
+// Validate reports every invalid option: a negative bandwidth, a minimum
+// above its maximum, or a malformed or duplicated filter name.
+func (ko *KurentoOptions) Validate() error {
+	var fe fieldErrors
+	ko.validate(&fe)
+	return fe.err()
+}
+
+func (ko *KurentoOptions) validate(fe *fieldErrors) {
+	bandwidths := []struct {
+		name  string
+		value *int32
+	}{
+		{"videoMaxRecvBandwidth", ko.VideoMaxRecvBandwidth},
+		{"videoMinRecvBandwidth", ko.VideoMinRecvBandwidth},
+		{"videoMaxSendBandwidth", ko.VideoMaxSendBandwidth},
+		{"videoMinSendBandwidth", ko.VideoMinSendBandwidth},
+	}
+	for _, b := range bandwidths {
+		if b.value != nil && *b.value < 0 {
+			fe.add("kurentoOptions."+b.name, "must not be negative, got %d", *b.value)
+		}
+	}
+
+	if bandwidthAbove(ko.VideoMinRecvBandwidth, ko.VideoMaxRecvBandwidth) {
+		fe.add("kurentoOptions.videoMinRecvBandwidth", "%d is above videoMaxRecvBandwidth %d",
+			*ko.VideoMinRecvBandwidth, *ko.VideoMaxRecvBandwidth)
+	}
+	if bandwidthAbove(ko.VideoMinSendBandwidth, ko.VideoMaxSendBandwidth) {
+		fe.add("kurentoOptions.videoMinSendBandwidth", "%d is above videoMaxSendBandwidth %d",
+			*ko.VideoMinSendBandwidth, *ko.VideoMaxSendBandwidth)
+	}
+
+	seen := make(map[string]bool, len(ko.AllowedFilters))
+	for _, f := range ko.AllowedFilters {
+		if !filterNamePattern.MatchString(f) {
+			fe.add("kurentoOptions.allowedFilters", "contains invalid filter name %q", f)
+		} else if seen[f] {
+			fe.add("kurentoOptions.allowedFilters", "contains %q twice", f)
+		}
+		seen[f] = true
+	}
+}
+
+// bandwidthAbove reports whether min is above max, 0 meaning unconstrained.
+func bandwidthAbove(min *int32, max *int32) bool {
+	return min != nil && max != nil && *max > 0 && *min > *max
+}
+
+func (ko *KurentoOptions) clone() *KurentoOptions {
+	c := *ko
+	if ko.AllowedFilters != nil {
+		c.AllowedFilters = append([]string(nil), ko.AllowedFilters...)
+	}
+	return &c
 }
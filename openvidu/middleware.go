@@ -0,0 +1,51 @@
+package openvidu
+
+import (
+	"net/http"
+)
+
+// Call is a REST call made by the client on behalf of an operation.
+type Call struct {
+	Operation Operation
+	Request   *http.Request
+}
+
+// Handler performs a Call and returns the response of the server.
+type Handler func(call *Call) (*http.Response, error)
+
+// Middleware wraps the Handler performing every call of the client, e.g. to
+// log, trace or rate limit calls, or to answer them without reaching the
+// server. A middleware returning a response must leave its body open for the
+// client to read and close.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middlewares around every call of the client. The first
+// middleware is the outermost one. Middlewares see each operation once;
+// retries happen inside the innermost handler.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *clientOptions) {
+		o.middlewares = append(o.middlewares, mw...)
+	}
+}
+
+// do performs the call for op, retrying it if the retry policy of the client
+// retries op.
+func (o *OpenVidu) do(op Operation, req *http.Request) (*http.Response, error) {
+	return o.send(op, req, o.retryPolicy.retries(op))
+}
+
+func (o *OpenVidu) send(op Operation, req *http.Request, retry bool) (*http.Response, error) {
+	client := o.httpClient
+	if op == OP_DOWNLOAD_RECORDING {
+		// Reading recording media can take longer than any timeout meant
+		// for API calls, so media requests are only bounded by their context.
+		client = o.mediaClient
+	}
+	h := func(call *Call) (*http.Response, error) {
+		return o.doWithRetry(client, call.Request, retry)
+	}
+	for i := len(o.middlewares) - 1; i >= 0; i-- {
+		h = o.middlewares[i](h)
+	}
+	return h(&Call{Operation: op, Request: req})
+}
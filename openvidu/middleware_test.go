@@ -0,0 +1,142 @@
+package openvidu_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+func TestMiddlewareOperations(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var operations []openvidu.Operation
+	var order []string
+	record := func(name string) openvidu.Middleware {
+		return func(next openvidu.Handler) openvidu.Handler {
+			return func(call *openvidu.Call) (*http.Response, error) {
+				mu.Lock()
+				order = append(order, name)
+				if name == "outer" {
+					operations = append(operations, call.Operation)
+				}
+				mu.Unlock()
+				return next(call)
+			}
+		}
+	}
+	ov := srv.Client(openvidu.WithMiddleware(record("outer"), record("inner")))
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.GenerateToken(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ov.Fetch(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := ov.StartRecordingById(session.SessionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ov.StopRecording(rec.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []openvidu.Operation{
+		openvidu.OP_CREATE_SESSION,
+		openvidu.OP_GENERATE_TOKEN,
+		openvidu.OP_FETCH_SESSIONS,
+		openvidu.OP_START_RECORDING,
+		openvidu.OP_STOP_RECORDING,
+		openvidu.OP_CLOSE_SESSION,
+	}
+	if !reflect.DeepEqual(operations, want) {
+		t.Errorf("operations = %v, want %v", operations, want)
+	}
+	if len(order) < 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("order = %v, want outer first", order)
+	}
+}
+
+func TestMiddlewareAnswersCall(t *testing.T) {
+	fake := func(next openvidu.Handler) openvidu.Handler {
+		return func(call *openvidu.Call) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       ioutil.NopCloser(strings.NewReader(`{"id":"ses_fake","createdAt":1}`)),
+				Request:    call.Request,
+			}, nil
+		}
+	}
+	ov := openvidu.NewOpenVidu("https://openvidu.invalid", "secret", openvidu.WithMiddleware(fake))
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.SessionId != "ses_fake" {
+		t.Errorf("SessionId = %q, want ses_fake", session.SessionId)
+	}
+}
+
+type fakeSessions struct {
+	openvidu.SessionAPI
+}
+
+func (f *fakeSessions) CreateSession0Context(ctx context.Context) (*openvidu.Session, error) {
+	return openvidu.NewSessionWithClient("ses_fake", &fakeSessionClient{}), nil
+}
+
+type fakeSessionClient struct {
+	openvidu.SessionClient
+	signals []string
+}
+
+func (f *fakeSessionClient) GenerateTokenContext(ctx context.Context, to *openvidu.TokenOptions) (*openvidu.Token, error) {
+	return &openvidu.Token{Token: "tok_fake", SessionId: "ses_fake"}, nil
+}
+
+func (f *fakeSessionClient) SignalByIdContext(ctx context.Context, signalType string, data string, connectionIds ...string) error {
+	f.signals = append(f.signals, signalType)
+	return nil
+}
+
+func TestFakeSessionAPI(t *testing.T) {
+	var api openvidu.SessionAPI = &fakeSessions{}
+
+	session, err := api.CreateSession0Context(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := session.GenerateToken(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Token != "tok_fake" {
+		t.Errorf("token = %q, want tok_fake", token.Token)
+	}
+	if err := session.Signal("chat", "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if len(session.GetActiveConnections()) != 0 {
+		t.Error("a session backed by a client has connections")
+	}
+}
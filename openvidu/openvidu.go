@@ -2,13 +2,14 @@ package openvidu
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
-	"time"
+	"sync"
 )
 
 const (
@@ -17,14 +18,29 @@ const (
 	API_RECORDINGS       = "api/recordings"
 	API_RECORDINGS_START = "/start"
 	API_RECORDINGS_STOP  = "/stop"
+	API_SIGNAL           = "api/signal"
 )
 
+// OpenVidu is a client for the OpenVidu REST API. It is safe for concurrent
+// use by multiple goroutines.
 type OpenVidu struct {
+	mu             sync.RWMutex
 	hostName       string
 	secret         string
 	activeSessions map[string]*Session
 	httpClient     *http.Client
+	mediaClient    *http.Client
 	basicAuth      string
+	userAgent      string
+	headers        http.Header
+	retryPolicy    *RetryPolicy
+	middlewares    []Middleware
+	serverVersion  string
+
+	// err is returned by every call when the options could not be applied.
+	err error
+
+	changeSubscribers changeSubscribers
 }
 
 type serverActiveSessions struct {
@@ -51,16 +67,27 @@ type connectionsInfo struct {
 }
 
 type connectionContent struct {
-	ConnectionId string        `json:"connectionId"`
-	CreatedAt    int64         `json:"createdAt"`
-	Location     string        `json:"location"`
-	Platform     string        `json:"platform"`
-	Token        string        `json:"token"`
-	Role         OpenViduRole  `json:"role"`
-	ServerData   string        `json:"serverData"`
-	ClientData   string        `json:"clientData"`
-	Publishers   []*publisher  `json:"publishers"`
-	Subscribers  []*subscriber `json:"subscribers"`
+	Id             string           `json:"id"`
+	ConnectionId   string           `json:"connectionId"`
+	Type           ConnectionType   `json:"type"`
+	Status         ConnectionStatus `json:"status"`
+	CreatedAt      int64            `json:"createdAt"`
+	ActiveAt       int64            `json:"activeAt"`
+	Location       string           `json:"location"`
+	Platform       string           `json:"platform"`
+	Token          string           `json:"token"`
+	Role           OpenViduRole     `json:"role"`
+	Record         bool             `json:"record"`
+	ServerData     string           `json:"serverData"`
+	ClientData     string           `json:"clientData"`
+	KurentoOptions *KurentoOptions  `json:"kurentoOptions"`
+	Publishers     []*publisher     `json:"publishers"`
+	Subscribers    []*subscriber    `json:"subscribers"`
+
+	RtspUri                 string `json:"rtspUri"`
+	AdaptativeBitrate       bool   `json:"adaptativeBitrate"`
+	OnlyPlayWithSubscribers bool   `json:"onlyPlayWithSubscribers"`
+	NetworkCache            int    `json:"networkCache"`
 }
 
 type subscriber struct {
@@ -86,16 +113,31 @@ type mediaOptions struct {
 	Filter          map[string]interface{} `json:"filter"`
 }
 
-func NewOpenVidu(hostName string, secret string) *OpenVidu {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+// NewOpenVidu creates a client for the OpenVidu server at hostName. The
+// server certificate is verified unless WithInsecureSkipVerify is given.
+func NewOpenVidu(hostName string, secret string, opts ...Option) *OpenVidu {
+	co := newClientOptions(opts)
+	httpClient, err := co.buildHttpClient()
+	var mediaClient *http.Client
+	if httpClient != nil {
+		c := *httpClient
+		c.Timeout = 0
+		mediaClient = &c
 	}
 
 	openVidu := &OpenVidu{
-		hostName:   hostName,
-		secret:     secret,
-		httpClient: &http.Client{Transport: tr, Timeout: 30 * time.Second},
-		basicAuth:  base64.StdEncoding.EncodeToString([]byte("OPENVIDUAPP:" + secret)),
+		hostName:       hostName,
+		secret:         secret,
+		activeSessions: make(map[string]*Session),
+		httpClient:     httpClient,
+		mediaClient:    mediaClient,
+		basicAuth:      base64.StdEncoding.EncodeToString([]byte("OPENVIDUAPP:" + secret)),
+		userAgent:      co.userAgent,
+		headers:        co.headers,
+		serverVersion:  co.serverVersion,
+		retryPolicy:    co.retryPolicy,
+		middlewares:    co.middlewares,
+		err:            err,
 	}
 
 	if !strings.HasSuffix(openVidu.hostName, "/") {
@@ -106,27 +148,72 @@ func NewOpenVidu(hostName string, secret string) *OpenVidu {
 }
 
 func (o *OpenVidu) CreateSession0() (*Session, error) {
-	session, err := NewSession0(o)
-	if err != nil {
-		return nil, err
-	}
-	o.activeSessions[session.SessionId] = session
-	return session, nil
+	return o.CreateSession0Context(context.Background())
 }
 
+func (o *OpenVidu) CreateSession0Context(ctx context.Context) (*Session, error) {
+	return o.CreateSession1Context(ctx, defaultSessionProperties())
+}
+
+// CreateSession1 creates a session with the given properties. Creating a
+// session with the CustomSessionId of an existing session is not an error:
+// the server answers 409 Conflict and the call resolves to that session.
 func (o *OpenVidu) CreateSession1(properties *SessionProperties) (*Session, error) {
-	session, err := NewSession1(o, properties)
+	return o.CreateSession1Context(context.Background(), properties)
+}
+
+func (o *OpenVidu) CreateSession1Context(ctx context.Context, properties *SessionProperties) (*Session, error) {
+	session, err := newSession(ctx, o, properties)
 	if err != nil {
 		return nil, err
 	}
-	o.activeSessions[session.SessionId] = session
+
+	o.mu.Lock()
+	existing := o.activeSessions[session.SessionId]
+	if existing == nil {
+		o.activeSessions[session.SessionId] = session
+	}
+	o.mu.Unlock()
+
+	if existing != nil {
+		// Keep the known state of a session created again, e.g. through the
+		// 409 Conflict of an existing custom session id.
+		createdAt := session.GetCreatedAt()
+		o.publishChanges(existing.update(func() {
+			if createdAt != 0 {
+				existing.createdAt = createdAt
+			}
+		}))
+		return existing, nil
+	}
+
+	o.publishChanges(diffSessionState(session.SessionId, nil, session.snapshotState()))
 	return session, nil
 }
 
 func (o *OpenVidu) StartRecording(sessionId string, properties *RecordingProperties) (*Recording, error) {
+	return o.StartRecordingContext(context.Background(), sessionId, properties)
+}
+
+func (o *OpenVidu) StartRecordingContext(ctx context.Context, sessionId string, properties *RecordingProperties) (*Recording, error) {
+	if properties == nil {
+		properties = (&RecordingProperties{
+			OutputMode: COMPOSED,
+			HasAudio:   true,
+			HasVideo:   true,
+		}).Build()
+	}
+	if err := properties.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := o.checkRecordingOptions(properties); err != nil {
+		return nil, err
+	}
+
 	url := o.hostName + API_RECORDINGS + API_RECORDINGS_START
-	rj := &recordingJson{
-		SessionId:  sessionId,
+	obj := &recordingRequest{
+		Session:    sessionId,
 		Name:       properties.Name,
 		OutputMode: properties.OutputMode,
 		HasAudio:   properties.HasAudio,
@@ -134,26 +221,33 @@ func (o *OpenVidu) StartRecording(sessionId string, properties *RecordingPropert
 	}
 
 	if properties.OutputMode == COMPOSED && properties.HasVideo {
-		rj.Resolution = properties.Resolution
-		rj.RecordingLayout = properties.RecordingLayout
+		obj.Resolution = properties.Resolution
+		obj.RecordingLayout = properties.RecordingLayout
 		if properties.RecordingLayout == CUSTOM {
-			rj.CustomLayout = properties.CustomLayout
+			obj.CustomLayout = properties.CustomLayout
 		}
+		obj.FrameRate = properties.FrameRate
+		obj.ShmSize = properties.ShmSize
+	}
+	if properties.OutputMode == INDIVIDUAL {
+		obj.IgnoreFailedStreams = properties.IgnoreFailedStreams
+	}
+	if len(properties.MediaNode) > 0 {
+		obj.MediaNode = &mediaNodeJson{Id: properties.MediaNode}
 	}
 
-	reqString, err := json.Marshal(rj)
+	reqString, err := json.Marshal(obj)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqString))
+	req, err := o.newRequest(ctx, "POST", url, bytes.NewBuffer(reqString))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Basic "+o.basicAuth)
-	response, err := o.httpClient.Do(req)
+	response, err := o.do(OP_START_RECORDING, req)
 	if err != nil {
 		return nil, err
 	}
@@ -173,17 +267,21 @@ func (o *OpenVidu) StartRecording(sessionId string, properties *RecordingPropert
 
 		r := NewRecording(rj)
 
-		activeSession := o.activeSessions[r.SessionId]
+		activeSession := o.GetActiveSession(r.SessionId)
 		if activeSession != nil {
-			activeSession.Recording = true
+			activeSession.setRecording(true)
 		}
 		return r, nil
 	} else {
-		return nil, newOpenViduError(statusCode)
+		return nil, newAPIError(OP_START_RECORDING, response)
 	}
 }
 
 func (o *OpenVidu) StartRecordingByName(sessionId string, name string) (*Recording, error) {
+	return o.StartRecordingByNameContext(context.Background(), sessionId, name)
+}
+
+func (o *OpenVidu) StartRecordingByNameContext(ctx context.Context, sessionId string, name string) (*Recording, error) {
 	rp := &RecordingProperties{
 		Name:       name,
 		OutputMode: COMPOSED,
@@ -191,22 +289,29 @@ func (o *OpenVidu) StartRecordingByName(sessionId string, name string) (*Recordi
 		HasVideo:   true,
 	}
 
-	return o.StartRecording(sessionId, rp.Build())
+	return o.StartRecordingContext(ctx, sessionId, rp.Build())
 }
 
 func (o *OpenVidu) StartRecordingById(sessionId string) (*Recording, error) {
 	return o.StartRecordingByName(sessionId, "")
 }
 
-func (o *OpenVidu) StopRecording(recordingId string) (*Recording, error)  {
+func (o *OpenVidu) StartRecordingByIdContext(ctx context.Context, sessionId string) (*Recording, error) {
+	return o.StartRecordingByNameContext(ctx, sessionId, "")
+}
+
+func (o *OpenVidu) StopRecording(recordingId string) (*Recording, error) {
+	return o.StopRecordingContext(context.Background(), recordingId)
+}
+
+func (o *OpenVidu) StopRecordingContext(ctx context.Context, recordingId string) (*Recording, error) {
 	url := o.hostName + API_RECORDINGS + API_RECORDINGS_STOP + "/" + recordingId
-	req, err := http.NewRequest("POST", url, nil)
+	req, err := o.newRequest(ctx, "POST", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+o.basicAuth)
-	response, err := o.httpClient.Do(req)
+	response, err := o.do(OP_STOP_RECORDING, req)
 	if err != nil {
 		return nil, err
 	}
@@ -225,25 +330,28 @@ func (o *OpenVidu) StopRecording(recordingId string) (*Recording, error)  {
 		}
 
 		r := NewRecording(rj)
-		activeSession := o.activeSessions[r.SessionId]
+		activeSession := o.GetActiveSession(r.SessionId)
 		if activeSession != nil {
-			activeSession.Recording = false
+			activeSession.setRecording(false)
 		}
 		return r, nil
 	} else {
-		return nil, newOpenViduError(statusCode)
+		return nil, newAPIError(OP_STOP_RECORDING, response)
 	}
 }
 
 func (o *OpenVidu) GetRecording(recordingId string) (*Recording, error) {
+	return o.GetRecordingContext(context.Background(), recordingId)
+}
+
+func (o *OpenVidu) GetRecordingContext(ctx context.Context, recordingId string) (*Recording, error) {
 	url := o.hostName + API_RECORDINGS + "/" + recordingId
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := o.newRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+o.basicAuth)
-	response, err := o.httpClient.Do(req)
+	response, err := o.do(OP_GET_RECORDING, req)
 	if err != nil {
 		return nil, err
 	}
@@ -264,61 +372,30 @@ func (o *OpenVidu) GetRecording(recordingId string) (*Recording, error) {
 		r := NewRecording(rj)
 		return r, nil
 	} else {
-		return nil, newOpenViduError(statusCode)
+		return nil, newAPIError(OP_GET_RECORDING, response)
 	}
 }
 
 func (o *OpenVidu) ListRecording() ([]*Recording, error) {
-	url := o.hostName + API_RECORDINGS
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Authorization", "Basic "+o.basicAuth)
-	response, err := o.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-
-	statusCode := response.StatusCode
-	if statusCode == http.StatusOK {
-		body, err := ioutil.ReadAll(response.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		obj := struct {
-			Items	[]*recordingJson	`json:"items"`
-		}{}
-
-		err = json.Unmarshal(body, &obj)
-		if err != nil {
-			return nil, err
-		}
-
-		var recordings []*Recording
-		for _, item := range obj.Items {
-			r := NewRecording(item)
-			recordings = append(recordings, r)
-		}
+	return o.ListRecordingContext(context.Background())
+}
 
-		return recordings, nil
-	} else {
-		return nil, newOpenViduError(statusCode)
-	}
+func (o *OpenVidu) ListRecordingContext(ctx context.Context) ([]*Recording, error) {
+	return o.QueryRecordingsContext(ctx, nil)
 }
 
 func (o *OpenVidu) DeleteRecording(recordingId string) error {
+	return o.DeleteRecordingContext(context.Background(), recordingId)
+}
+
+func (o *OpenVidu) DeleteRecordingContext(ctx context.Context, recordingId string) error {
 	url := o.hostName + API_RECORDINGS + "/" + recordingId
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := o.newRequest(ctx, "DELETE", url, nil)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Authorization", "Basic "+o.basicAuth)
-	response, err := o.httpClient.Do(req)
+	response, err := o.do(OP_DELETE_RECORDING, req)
 	if err != nil {
 		return err
 	}
@@ -326,13 +403,39 @@ func (o *OpenVidu) DeleteRecording(recordingId string) error {
 
 	statusCode := response.StatusCode
 	if statusCode != http.StatusNoContent {
-		return newOpenViduError(statusCode)
+		return newAPIError(OP_DELETE_RECORDING, response)
 	} else {
 		return nil
 	}
 }
 
+func (o *OpenVidu) newRequest(ctx context.Context, method string, url string, body io.Reader) (*http.Request, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range o.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if len(o.userAgent) > 0 {
+		req.Header.Set("User-Agent", o.userAgent)
+	}
+	req.Header.Set("Authorization", "Basic "+o.basicAuth)
+	return req, nil
+}
+
+// GetActiveSessions returns the sessions known to the client. The returned
+// slice is a snapshot and can be modified freely.
 func (o *OpenVidu) GetActiveSessions() []*Session {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
 	var sessions []*Session
 	for _, v := range o.activeSessions {
 		sessions = append(sessions, v)
@@ -340,17 +443,49 @@ func (o *OpenVidu) GetActiveSessions() []*Session {
 	return sessions
 }
 
+// GetActiveSession returns the known session with the given id, or nil.
+func (o *OpenVidu) GetActiveSession(sessionId string) *Session {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.activeSessions[sessionId]
+}
+
+func (o *OpenVidu) removeActiveSession(sessionId string) {
+	o.mu.Lock()
+	s := o.activeSessions[sessionId]
+	delete(o.activeSessions, sessionId)
+	o.mu.Unlock()
+
+	if s != nil {
+		o.publishChanges(diffSessionState(sessionId, s.snapshotState(), nil))
+	}
+}
+
 func (o *OpenVidu) Fetch() (bool, error) {
+	return o.FetchContext(context.Background())
+}
+
+func (o *OpenVidu) FetchContext(ctx context.Context) (bool, error) {
+	changes, err := o.FetchChangesContext(ctx)
+	return len(changes) > 0, err
+}
+
+// FetchChanges updates the active sessions with their state on the server
+// and returns what changed.
+func (o *OpenVidu) FetchChanges() ([]*Change, error) {
+	return o.FetchChangesContext(context.Background())
+}
+
+func (o *OpenVidu) FetchChangesContext(ctx context.Context) ([]*Change, error) {
 	url := o.hostName + API_SESSIONS
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := o.newRequest(ctx, "GET", url, nil)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+o.basicAuth)
-	response, err := o.httpClient.Do(req)
+	response, err := o.do(OP_FETCH_SESSIONS, req)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	defer response.Body.Close()
 
@@ -358,32 +493,29 @@ func (o *OpenVidu) Fetch() (bool, error) {
 	if statusCode == http.StatusOK {
 		body, err := ioutil.ReadAll(response.Body)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
 		var sas *serverActiveSessions
 		err = json.Unmarshal(body, &sas)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
+		o.mu.Lock()
 		var fetchedSessionIds []string
-		hasChanged := false
+		var changes []*Change
 		for _, session := range sas.Content {
 			sessionId := session.SessionId
 			fetchedSessionIds = append(fetchedSessionIds, sessionId)
 			computeIfPresent(o.activeSessions, sessionId, func(sId string, s *Session) *Session {
-				beforeJSON, _ := s.ToJson()
-				s.resetSessionWithJson(session)
-				afterJSON, _ := s.ToJson()
-				changed := strings.Compare(beforeJSON, afterJSON) != 0
-				hasChanged = hasChanged || changed
+				changes = append(changes, s.applyServerSession(session)...)
 				return s
 			})
 
 			computeIfAbsent(o.activeSessions, sessionId, func(sId string) *Session {
-				hasChanged = true
 				s, _ := NewSession2(o, session)
+				changes = append(changes, diffSessionState(sId, nil, s.snapshotState())...)
 				return s
 			})
 		}
@@ -393,25 +525,31 @@ func (o *OpenVidu) Fetch() (bool, error) {
 			if contains(fetchedSessionIds, k) {
 				newActiveSessions[k] = v
 			} else {
-				hasChanged = true
+				changes = append(changes, diffSessionState(k, v.snapshotState(), nil)...)
 			}
 		}
 		o.activeSessions = newActiveSessions
-		return hasChanged, nil
+		o.mu.Unlock()
+
+		o.publishChanges(changes)
+		return changes, nil
 	} else {
-		return false, newOpenViduError(statusCode)
+		return nil, newAPIError(OP_FETCH_SESSIONS, response)
 	}
 }
 
 func (o *OpenVidu) FetchSessions() ([]*Session, error) {
+	return o.FetchSessionsContext(context.Background())
+}
+
+func (o *OpenVidu) FetchSessionsContext(ctx context.Context) ([]*Session, error) {
 	url := o.hostName + API_SESSIONS
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := o.newRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+o.basicAuth)
-	response, err := o.httpClient.Do(req)
+	response, err := o.do(OP_FETCH_SESSIONS, req)
 	if err != nil {
 		return nil, err
 	}
@@ -441,7 +579,7 @@ func (o *OpenVidu) FetchSessions() ([]*Session, error) {
 		}
 		return sessions, nil
 	} else {
-		return nil, newOpenViduError(statusCode)
+		return nil, newAPIError(OP_FETCH_SESSIONS, response)
 	}
 }
 
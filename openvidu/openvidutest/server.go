@@ -0,0 +1,1183 @@
+// Package openvidutest provides an in-memory OpenVidu server for testing
+// code using the openvidu package without a real OpenVidu deployment.
+package openvidutest
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+)
+
+var resolutionPattern = regexp.MustCompile(`^\d{3,4}x\d{3,4}$`)
+
+// Server is a fake OpenVidu server implementing the REST endpoints used by
+// the openvidu package. Its state only changes through those endpoints and
+// the simulation methods, such as Join, Publish and SetRecordingStatus.
+type Server struct {
+	*httptest.Server
+
+	// RecordingStartStatus is the status given to recordings when started.
+	// It defaults to openvidu.STARTED; use openvidu.STARTING to simulate a
+	// recording taking time to start.
+	RecordingStartStatus openvidu.RecordingStatus
+
+	mu         sync.Mutex
+	secret     string
+	nextId     int
+	sessions   map[string]*session
+	tokens     map[string]*token
+	recordings map[string]*recording
+	media      map[string][]byte
+	signals    []*Signal
+}
+
+// Signal is a signal sent through the REST API.
+type Signal struct {
+	Session string   `json:"session"`
+	To      []string `json:"to"`
+	Type    string   `json:"type"`
+	Data    string   `json:"data"`
+}
+
+type session struct {
+	id          string
+	createdAt   int64
+	properties  openvidu.SessionProperties
+	connections map[string]*connection
+	recording   string
+}
+
+type connection struct {
+	id             string
+	sessionId      string
+	connectionType openvidu.ConnectionType
+	status         openvidu.ConnectionStatus
+	createdAt      int64
+	activeAt       int64
+	record         bool
+	kurentoOptions *openvidu.KurentoOptions
+	token          string
+	role           openvidu.OpenViduRole
+	serverData     string
+	clientData     string
+	location       string
+	platform       string
+	publishers     map[string]*publisher
+	subscribers    map[string]*subscriber
+
+	rtspUri                 string
+	adaptativeBitrate       bool
+	onlyPlayWithSubscribers bool
+	networkCache            int
+}
+
+type publisher struct {
+	StreamId     string        `json:"streamId"`
+	CreatedAt    int64         `json:"createdAt"`
+	MediaOptions *MediaOptions `json:"mediaOptions"`
+}
+
+type subscriber struct {
+	StreamId  string `json:"streamId"`
+	CreatedAt int64  `json:"createdAt"`
+	Publisher string `json:"publisher"`
+}
+
+// MediaOptions describe the stream published by Publish.
+type MediaOptions struct {
+	HasAudio        bool   `json:"hasAudio"`
+	AudioActive     bool   `json:"audioActive"`
+	HasVideo        bool   `json:"hasVideo"`
+	VideoActive     bool   `json:"videoActive"`
+	TypeOfVideo     string `json:"typeOfVideo"`
+	FrameRate       int32  `json:"frameRate"`
+	VideoDimensions string `json:"videoDimensions"`
+}
+
+type token struct {
+	Id             string                   `json:"id"`
+	Token          string                   `json:"token"`
+	Session        string                   `json:"session"`
+	Role           openvidu.OpenViduRole    `json:"role"`
+	Data           string                   `json:"data"`
+	KurentoOptions *openvidu.KurentoOptions `json:"kurentoOptions,omitempty"`
+	CreatedAt      int64                    `json:"createdAt"`
+}
+
+type recording struct {
+	Id              string                   `json:"id"`
+	SessionId       string                   `json:"sessionId"`
+	Name            string                   `json:"name"`
+	OutputMode      openvidu.OutputMode      `json:"outputMode"`
+	HasAudio        bool                     `json:"hasAudio"`
+	HasVideo        bool                     `json:"hasVideo"`
+	Resolution      string                   `json:"resolution,omitempty"`
+	RecordingLayout openvidu.RecordingLayout `json:"recordingLayout,omitempty"`
+	CustomLayout    string                   `json:"customLayout,omitempty"`
+	CreatedAt       int64                    `json:"createdAt"`
+	Size            int64                    `json:"size"`
+	Duration        float64                  `json:"duration"`
+	Url             string                   `json:"url,omitempty"`
+	Status          openvidu.RecordingStatus `json:"status"`
+
+	FrameRate           int        `json:"frameRate,omitempty"`
+	ShmSize             int64      `json:"shmSize,omitempty"`
+	IgnoreFailedStreams bool       `json:"ignoreFailedStreams,omitempty"`
+	MediaNode           *mediaNode `json:"mediaNode,omitempty"`
+}
+
+type mediaNode struct {
+	Id string `json:"id"`
+}
+
+// NewServer starts a fake OpenVidu server accepting the given secret. The
+// caller should call Close when done.
+func NewServer(secret string) *Server {
+	s := &Server{
+		RecordingStartStatus: openvidu.STARTED,
+		secret:               secret,
+		sessions:             make(map[string]*session),
+		tokens:               make(map[string]*token),
+		recordings:           make(map[string]*recording),
+		media:                make(map[string][]byte),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an OpenVidu client configured for the server.
+func (s *Server) Client(opts ...openvidu.Option) *openvidu.OpenVidu {
+	return openvidu.NewOpenVidu(s.URL, s.secret, opts...)
+}
+
+// Join simulates a participant connecting to a session with a token created
+// through the REST API, and returns the id of the connection. Tokens of
+// pending connections activate them.
+func (s *Server) Join(tokenString string, clientData string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ses := range s.sessions {
+		for _, c := range ses.connections {
+			if c.token == tokenString && c.status == openvidu.PENDING {
+				c.status = openvidu.ACTIVE
+				c.activeAt = now()
+				c.clientData = clientData
+				return c.id, nil
+			}
+		}
+	}
+
+	t := s.tokens[tokenString]
+	if t == nil {
+		return "", fmt.Errorf("openvidutest: unknown token %q", tokenString)
+	}
+	ses := s.sessions[t.Session]
+	if ses == nil {
+		return "", fmt.Errorf("openvidutest: session %q is closed", t.Session)
+	}
+	delete(s.tokens, tokenString)
+
+	c := s.newConnection(ses.id, t.Role, t.Data, clientData)
+	c.token = t.Token
+	c.kurentoOptions = t.KurentoOptions
+	ses.connections[c.id] = c
+	return c.id, nil
+}
+
+// JoinSession simulates a participant connecting to a session without
+// generating a token first, and returns the id of the new connection.
+func (s *Server) JoinSession(sessionId string, role openvidu.OpenViduRole, serverData string, clientData string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ses := s.sessions[sessionId]
+	if ses == nil {
+		return "", fmt.Errorf("openvidutest: unknown session %q", sessionId)
+	}
+
+	c := s.newConnection(ses.id, role, serverData, clientData)
+	c.token = s.tokenUrl(sessionId, s.newId("tok"))
+	ses.connections[c.id] = c
+	return c.id, nil
+}
+
+// Leave simulates a participant leaving a session.
+func (s *Server) Leave(sessionId string, connectionId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ses := s.sessions[sessionId]
+	if ses == nil || ses.connections[connectionId] == nil {
+		return fmt.Errorf("openvidutest: unknown connection %q in session %q", connectionId, sessionId)
+	}
+	ses.removeConnection(connectionId)
+	return nil
+}
+
+// Publish simulates a participant publishing a stream, and returns the id of
+// the new stream. A nil media options publishes camera audio and video.
+func (s *Server) Publish(sessionId string, connectionId string, mo *MediaOptions) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ses := s.sessions[sessionId]
+	if ses == nil || ses.connections[connectionId] == nil {
+		return "", fmt.Errorf("openvidutest: unknown connection %q in session %q", connectionId, sessionId)
+	}
+	c := ses.connections[connectionId]
+	if c.status != openvidu.ACTIVE {
+		return "", fmt.Errorf("openvidutest: connection %q is %s", connectionId, c.status)
+	}
+	if c.role == openvidu.SUBSCRIBER {
+		return "", fmt.Errorf("openvidutest: connection %q has role SUBSCRIBER", connectionId)
+	}
+
+	if mo == nil {
+		mo = &MediaOptions{
+			HasAudio:        true,
+			AudioActive:     true,
+			HasVideo:        true,
+			VideoActive:     true,
+			TypeOfVideo:     "CAMERA",
+			FrameRate:       30,
+			VideoDimensions: `{"width":640,"height":480}`,
+		}
+	}
+	streamId := "str_CAM_" + s.newId("") + "_" + connectionId
+	c.publishers[streamId] = &publisher{StreamId: streamId, CreatedAt: now(), MediaOptions: mo}
+	return streamId, nil
+}
+
+// Subscribe simulates a participant subscribing to a stream of the session.
+func (s *Server) Subscribe(sessionId string, connectionId string, streamId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ses := s.sessions[sessionId]
+	if ses == nil || ses.connections[connectionId] == nil {
+		return fmt.Errorf("openvidutest: unknown connection %q in session %q", connectionId, sessionId)
+	}
+	pc := ses.publisherConnection(streamId)
+	if pc == nil {
+		return fmt.Errorf("openvidutest: unknown stream %q in session %q", streamId, sessionId)
+	}
+
+	ses.connections[connectionId].subscribers[streamId] = &subscriber{StreamId: streamId, CreatedAt: now(), Publisher: pc.id}
+	return nil
+}
+
+// SetRecordingStatus moves a recording to the given status. Recordings
+// becoming READY get a duration, a url and the media set with
+// SetRecordingMedia, or 1KB of generated data.
+func (s *Server) SetRecordingStatus(recordingId string, status openvidu.RecordingStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.recordings[recordingId]
+	if r == nil {
+		return fmt.Errorf("openvidutest: unknown recording %q", recordingId)
+	}
+
+	r.Status = status
+	switch status {
+	case openvidu.STOPPED, openvidu.READY, openvidu.FAILED:
+		if ses := s.sessions[r.SessionId]; ses != nil && ses.recording == r.Id {
+			ses.recording = ""
+		}
+	}
+	if status == openvidu.READY {
+		r.Duration = float64(now()-r.CreatedAt) / 1000
+		if s.media[r.Id] == nil {
+			s.media[r.Id] = s.generateMedia(r)
+		}
+		r.Size = int64(len(s.media[r.Id]))
+		r.Url = s.URL + "/openvidu/recordings/" + r.Id + "/" + r.Name + r.extension()
+	}
+	return nil
+}
+
+// generateMedia returns 1KB of data for COMPOSED recordings, and for
+// INDIVIDUAL ones a ZIP file with the metadata and a file for each stream
+// currently published in the session.
+func (s *Server) generateMedia(r *recording) []byte {
+	data := make([]byte, 1024)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	if r.OutputMode != openvidu.INDIVIDUAL {
+		return data
+	}
+
+	var files []map[string]interface{}
+	if ses := s.sessions[r.SessionId]; ses != nil {
+		for _, c := range ses.connections {
+			for _, p := range c.publishers {
+				files = append(files, map[string]interface{}{
+					"name":            p.StreamId + ".webm",
+					"connectionId":    c.id,
+					"streamId":        p.StreamId,
+					"size":            len(data),
+					"clientData":      c.clientData,
+					"serverData":      c.serverData,
+					"hasAudio":        p.MediaOptions.HasAudio && r.HasAudio,
+					"hasVideo":        p.MediaOptions.HasVideo && r.HasVideo,
+					"typeOfVideo":     p.MediaOptions.TypeOfVideo,
+					"startTimeOffset": 0,
+					"endTimeOffset":   int64(r.Duration * 1000),
+				})
+			}
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i]["streamId"].(string) < files[j]["streamId"].(string) })
+	if files == nil {
+		files = make([]map[string]interface{}, 0)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, _ := zw.Create(f["name"].(string))
+		w.Write(data)
+	}
+	w, _ := zw.Create(r.Name + ".json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":        r.Id,
+		"name":      r.Name,
+		"sessionId": r.SessionId,
+		"createdAt": r.CreatedAt,
+		"files":     files,
+	})
+	zw.Close()
+	return buf.Bytes()
+}
+
+// SetRecordingMedia sets the media file served for a recording, e.g. a ZIP
+// file for INDIVIDUAL recordings. The size of a READY recording is updated
+// to match.
+func (s *Server) SetRecordingMedia(recordingId string, media []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.recordings[recordingId]
+	if r == nil {
+		return fmt.Errorf("openvidutest: unknown recording %q", recordingId)
+	}
+	s.media[recordingId] = media
+	if r.Status == openvidu.READY {
+		r.Size = int64(len(media))
+	}
+	return nil
+}
+
+// RecordingStatus returns the status of a recording, or "" if the server has
+// no such recording.
+func (s *Server) RecordingStatus(recordingId string) openvidu.RecordingStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r := s.recordings[recordingId]; r != nil {
+		return r.Status
+	}
+	return ""
+}
+
+// Signals returns the signals sent so far, oldest first.
+func (s *Server) Signals() []*Signal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Signal(nil), s.signals...)
+}
+
+// SessionIds returns the ids of the active sessions, sorted.
+func (s *Server) SessionIds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="openvidu"`)
+		writeError(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == openvidu.API_SESSIONS && r.Method == http.MethodPost:
+		s.createSession(w, r)
+	case path == openvidu.API_SESSIONS && r.Method == http.MethodGet:
+		s.listSessions(w, r)
+	case strings.HasPrefix(path, openvidu.API_SESSIONS+"/") && len(parts) == 3 && r.Method == http.MethodGet:
+		s.getSession(w, r, parts[2])
+	case strings.HasPrefix(path, openvidu.API_SESSIONS+"/") && len(parts) == 3 && r.Method == http.MethodDelete:
+		s.closeSession(w, r, parts[2])
+	case strings.HasPrefix(path, openvidu.API_SESSIONS+"/") && len(parts) == 4 && parts[3] == "connection" && r.Method == http.MethodPost:
+		s.createConnection(w, r, parts[2])
+	case strings.HasPrefix(path, openvidu.API_SESSIONS+"/") && len(parts) == 4 && parts[3] == "connection" && r.Method == http.MethodGet:
+		s.listConnections(w, r, parts[2])
+	case strings.HasPrefix(path, openvidu.API_SESSIONS+"/") && len(parts) == 5 && parts[3] == "connection" && r.Method == http.MethodGet:
+		s.getConnection(w, r, parts[2], parts[4])
+	case strings.HasPrefix(path, openvidu.API_SESSIONS+"/") && len(parts) == 5 && parts[3] == "connection" && r.Method == http.MethodPatch:
+		s.updateConnection(w, r, parts[2], parts[4])
+	case strings.HasPrefix(path, openvidu.API_SESSIONS+"/") && len(parts) == 5 && parts[3] == "connection" && r.Method == http.MethodDelete:
+		s.deleteConnection(w, r, parts[2], parts[4])
+	case strings.HasPrefix(path, openvidu.API_SESSIONS+"/") && len(parts) == 5 && parts[3] == "stream" && r.Method == http.MethodDelete:
+		s.deleteStream(w, r, parts[2], parts[4])
+	case path == openvidu.API_SIGNAL && r.Method == http.MethodPost:
+		s.sendSignal(w, r)
+	case path == openvidu.API_TOKENS && r.Method == http.MethodPost:
+		s.createToken(w, r)
+	case path == openvidu.API_RECORDINGS+openvidu.API_RECORDINGS_START && r.Method == http.MethodPost:
+		s.startRecording(w, r)
+	case strings.HasPrefix(path, openvidu.API_RECORDINGS+openvidu.API_RECORDINGS_STOP+"/") && len(parts) == 4 && r.Method == http.MethodPost:
+		s.stopRecording(w, r, parts[3])
+	case path == openvidu.API_RECORDINGS && r.Method == http.MethodGet:
+		s.listRecordings(w, r)
+	case strings.HasPrefix(path, openvidu.API_RECORDINGS+"/") && len(parts) == 3 && r.Method == http.MethodGet:
+		s.getRecording(w, r, parts[2])
+	case strings.HasPrefix(path, openvidu.API_RECORDINGS+"/") && len(parts) == 3 && r.Method == http.MethodDelete:
+		s.deleteRecording(w, r, parts[2])
+	case strings.HasPrefix(path, "openvidu/recordings/") && len(parts) == 4 && r.Method == http.MethodGet:
+		s.serveRecordingMedia(w, r, parts[2], parts[3])
+	default:
+		writeError(w, r, http.StatusNotFound, "no handler for "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("OPENVIDUAPP:"+s.secret))
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) == 1
+}
+
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		MediaMode              openvidu.MediaMode       `json:"mediaMode"`
+		RecordingMode          openvidu.RecordingMode   `json:"recordingMode"`
+		DefaultOutputMode      openvidu.OutputMode      `json:"defaultOutputMode"`
+		DefaultRecordingLayout openvidu.RecordingLayout `json:"defaultRecordingLayout"`
+		DefaultCustomLayout    string                   `json:"defaultCustomLayout"`
+		CustomSessionId        string                   `json:"customSessionId"`
+	}
+	if r.ContentLength != 0 && !decode(w, r, &body) {
+		return
+	}
+
+	if len(body.CustomSessionId) > 0 && s.sessions[body.CustomSessionId] != nil {
+		writeError(w, r, http.StatusConflict, "session "+body.CustomSessionId+" already exists")
+		return
+	}
+
+	ses := &session{
+		id:        body.CustomSessionId,
+		createdAt: now(),
+		properties: openvidu.SessionProperties{
+			MediaMode:              openvidu.MediaMode(orDefault(string(body.MediaMode), string(openvidu.ROUTED))),
+			RecordingMode:          openvidu.RecordingMode(orDefault(string(body.RecordingMode), string(openvidu.MANUAL))),
+			DefaultOutputMode:      openvidu.OutputMode(orDefault(string(body.DefaultOutputMode), string(openvidu.COMPOSED))),
+			DefaultRecordingLayout: openvidu.RecordingLayout(orDefault(string(body.DefaultRecordingLayout), string(openvidu.BEST_FIT))),
+			DefaultCustomLayout:    body.DefaultCustomLayout,
+			CustomSessionId:        body.CustomSessionId,
+		},
+		connections: make(map[string]*connection),
+	}
+	if len(ses.id) == 0 {
+		ses.id = s.newId("ses")
+	}
+	s.sessions[ses.id] = ses
+
+	writeJson(w, http.StatusOK, map[string]interface{}{"id": ses.id, "createdAt": ses.createdAt})
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	content := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		content = append(content, s.sessions[id].toJson())
+	}
+	writeJson(w, http.StatusOK, map[string]interface{}{"numberOfElements": len(content), "content": content})
+}
+
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request, sessionId string) {
+	ses := s.sessions[sessionId]
+	if ses == nil {
+		writeError(w, r, http.StatusNotFound, "session "+sessionId+" not found")
+		return
+	}
+	writeJson(w, http.StatusOK, ses.toJson())
+}
+
+func (s *Server) closeSession(w http.ResponseWriter, r *http.Request, sessionId string) {
+	ses := s.sessions[sessionId]
+	if ses == nil {
+		writeError(w, r, http.StatusNotFound, "session "+sessionId+" not found")
+		return
+	}
+	if rec := s.recordings[ses.recording]; rec != nil {
+		rec.Status = openvidu.STOPPED
+	}
+	delete(s.sessions, sessionId)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) createConnection(w http.ResponseWriter, r *http.Request, sessionId string) {
+	var body struct {
+		Type                    openvidu.ConnectionType  `json:"type"`
+		Data                    string                   `json:"data"`
+		Role                    openvidu.OpenViduRole    `json:"role"`
+		Record                  *bool                    `json:"record"`
+		KurentoOptions          *openvidu.KurentoOptions `json:"kurentoOptions"`
+		RtspUri                 string                   `json:"rtspUri"`
+		Audio                   *bool                    `json:"audio"`
+		Video                   *bool                    `json:"video"`
+		AdaptativeBitrate       *bool                    `json:"adaptativeBitrate"`
+		OnlyPlayWithSubscribers *bool                    `json:"onlyPlayWithSubscribers"`
+		NetworkCache            *int                     `json:"networkCache"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	if len(body.Type) == 0 {
+		body.Type = openvidu.WEBRTC
+	}
+	if body.Type != openvidu.WEBRTC && body.Type != openvidu.IPCAM {
+		writeError(w, r, http.StatusBadRequest, "unsupported connection type "+string(body.Type))
+		return
+	}
+	if body.Type == openvidu.IPCAM {
+		if !strings.HasPrefix(body.RtspUri, "rtsp://") && !strings.HasPrefix(body.RtspUri, "rtsps://") {
+			writeError(w, r, http.StatusBadRequest, "invalid rtspUri "+body.RtspUri)
+			return
+		}
+		if body.NetworkCache != nil && *body.NetworkCache < 0 {
+			writeError(w, r, http.StatusBadRequest, "networkCache must not be negative")
+			return
+		}
+	}
+	if len(body.Role) == 0 {
+		body.Role = openvidu.PUBLISHER
+	}
+	if !validRole(body.Role) {
+		writeError(w, r, http.StatusBadRequest, "invalid role "+string(body.Role))
+		return
+	}
+
+	ses := s.sessions[sessionId]
+	if ses == nil {
+		writeError(w, r, http.StatusNotFound, "session "+sessionId+" not found")
+		return
+	}
+
+	if body.Type == openvidu.IPCAM {
+		c := s.newConnection(ses.id, openvidu.PUBLISHER, body.Data, "")
+		c.id = s.newId("ipc_IPCAM")
+		c.connectionType = openvidu.IPCAM
+		c.record = body.Record == nil || *body.Record
+		c.platform = "IPCAM"
+		c.rtspUri = body.RtspUri
+		c.adaptativeBitrate = body.AdaptativeBitrate == nil || *body.AdaptativeBitrate
+		c.onlyPlayWithSubscribers = body.OnlyPlayWithSubscribers == nil || *body.OnlyPlayWithSubscribers
+		c.networkCache = 2000
+		if body.NetworkCache != nil {
+			c.networkCache = *body.NetworkCache
+		}
+		hasAudio := body.Audio == nil || *body.Audio
+		hasVideo := body.Video == nil || *body.Video
+		streamId := "str_IPC_" + s.newId("") + "_" + c.id
+		c.publishers[streamId] = &publisher{
+			StreamId:  streamId,
+			CreatedAt: c.createdAt,
+			MediaOptions: &MediaOptions{
+				HasAudio:    hasAudio,
+				AudioActive: hasAudio,
+				HasVideo:    hasVideo,
+				VideoActive: hasVideo,
+				TypeOfVideo: "IPCAM",
+			},
+		}
+		ses.connections[c.id] = c
+		writeJson(w, http.StatusOK, c.toJson())
+		return
+	}
+
+	c := s.newConnection(ses.id, body.Role, body.Data, "")
+	c.status = openvidu.PENDING
+	c.token = s.tokenUrl(ses.id, s.newId("tok"))
+	c.record = body.Record == nil || *body.Record
+	c.kurentoOptions = body.KurentoOptions
+	ses.connections[c.id] = c
+	writeJson(w, http.StatusOK, c.toJson())
+}
+
+func (s *Server) listConnections(w http.ResponseWriter, r *http.Request, sessionId string) {
+	ses := s.sessions[sessionId]
+	if ses == nil {
+		writeError(w, r, http.StatusNotFound, "session "+sessionId+" not found")
+		return
+	}
+	content := ses.connectionsJson()
+	writeJson(w, http.StatusOK, map[string]interface{}{"numberOfElements": len(content), "content": content})
+}
+
+func (s *Server) getConnection(w http.ResponseWriter, r *http.Request, sessionId string, connectionId string) {
+	ses := s.sessions[sessionId]
+	if ses == nil {
+		writeError(w, r, http.StatusBadRequest, "session "+sessionId+" not found")
+		return
+	}
+	c := ses.connections[connectionId]
+	if c == nil {
+		writeError(w, r, http.StatusNotFound, "connection "+connectionId+" not found")
+		return
+	}
+	writeJson(w, http.StatusOK, c.toJson())
+}
+
+func (s *Server) updateConnection(w http.ResponseWriter, r *http.Request, sessionId string, connectionId string) {
+	var body struct {
+		Role   openvidu.OpenViduRole `json:"role"`
+		Record *bool                 `json:"record"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	if len(body.Role) > 0 && !validRole(body.Role) {
+		writeError(w, r, http.StatusBadRequest, "invalid role "+string(body.Role))
+		return
+	}
+
+	ses := s.sessions[sessionId]
+	if ses == nil {
+		writeError(w, r, http.StatusNotFound, "session "+sessionId+" not found")
+		return
+	}
+	c := ses.connections[connectionId]
+	if c == nil {
+		writeError(w, r, http.StatusNotFound, "connection "+connectionId+" not found")
+		return
+	}
+
+	if len(body.Role) > 0 {
+		c.role = body.Role
+	}
+	if body.Record != nil {
+		c.record = *body.Record
+	}
+	writeJson(w, http.StatusOK, c.toJson())
+}
+
+func (s *Server) deleteConnection(w http.ResponseWriter, r *http.Request, sessionId string, connectionId string) {
+	ses := s.sessions[sessionId]
+	if ses == nil {
+		writeError(w, r, http.StatusBadRequest, "session "+sessionId+" not found")
+		return
+	}
+	if ses.connections[connectionId] == nil {
+		writeError(w, r, http.StatusNotFound, "connection "+connectionId+" not found")
+		return
+	}
+	ses.removeConnection(connectionId)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteStream(w http.ResponseWriter, r *http.Request, sessionId string, streamId string) {
+	ses := s.sessions[sessionId]
+	if ses == nil {
+		writeError(w, r, http.StatusBadRequest, "session "+sessionId+" not found")
+		return
+	}
+	if ses.publisherConnection(streamId) == nil {
+		writeError(w, r, http.StatusNotFound, "stream "+streamId+" not found")
+		return
+	}
+	ses.removeStream(streamId)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) sendSignal(w http.ResponseWriter, r *http.Request) {
+	var body Signal
+	if !decode(w, r, &body) {
+		return
+	}
+	if len(body.Session) == 0 {
+		writeError(w, r, http.StatusBadRequest, "session is required")
+		return
+	}
+
+	ses := s.sessions[body.Session]
+	if ses == nil {
+		writeError(w, r, http.StatusNotFound, "session "+body.Session+" not found")
+		return
+	}
+	active := 0
+	for _, c := range ses.connections {
+		if c.status == openvidu.ACTIVE {
+			active++
+		}
+	}
+	if active == 0 {
+		writeError(w, r, http.StatusNotAcceptable, "session "+body.Session+" has no connected participants")
+		return
+	}
+	for _, connectionId := range body.To {
+		if c := ses.connections[connectionId]; c == nil || c.status != openvidu.ACTIVE {
+			writeError(w, r, http.StatusNotAcceptable, "connection "+connectionId+" not found")
+			return
+		}
+	}
+
+	s.signals = append(s.signals, &body)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) createToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Session        string                   `json:"session"`
+		Role           openvidu.OpenViduRole    `json:"role"`
+		Data           string                   `json:"data"`
+		KurentoOptions *openvidu.KurentoOptions `json:"kurentoOptions"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	if len(body.Session) == 0 {
+		writeError(w, r, http.StatusBadRequest, "session is required")
+		return
+	}
+	if len(body.Role) == 0 {
+		body.Role = openvidu.PUBLISHER
+	}
+	if !validRole(body.Role) {
+		writeError(w, r, http.StatusBadRequest, "invalid role "+string(body.Role))
+		return
+	}
+	if s.sessions[body.Session] == nil {
+		writeError(w, r, http.StatusNotFound, "session "+body.Session+" not found")
+		return
+	}
+
+	tokenUrl := s.tokenUrl(body.Session, s.newId("tok"))
+	t := &token{
+		Id:             tokenUrl,
+		Token:          tokenUrl,
+		Session:        body.Session,
+		Role:           body.Role,
+		Data:           body.Data,
+		KurentoOptions: body.KurentoOptions,
+		CreatedAt:      now(),
+	}
+	s.tokens[tokenUrl] = t
+	writeJson(w, http.StatusOK, t)
+}
+
+func (s *Server) startRecording(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Session         string                   `json:"session"`
+		Name            string                   `json:"name"`
+		OutputMode      openvidu.OutputMode      `json:"outputMode"`
+		HasAudio        *bool                    `json:"hasAudio"`
+		HasVideo        *bool                    `json:"hasVideo"`
+		Resolution      string                   `json:"resolution"`
+		RecordingLayout openvidu.RecordingLayout `json:"recordingLayout"`
+		CustomLayout    string                   `json:"customLayout"`
+
+		FrameRate           int        `json:"frameRate"`
+		ShmSize             int64      `json:"shmSize"`
+		IgnoreFailedStreams bool       `json:"ignoreFailedStreams"`
+		MediaNode           *mediaNode `json:"mediaNode"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+
+	if len(body.Session) == 0 {
+		writeError(w, r, http.StatusBadRequest, "session is required")
+		return
+	}
+	hasAudio := body.HasAudio == nil || *body.HasAudio
+	hasVideo := body.HasVideo == nil || *body.HasVideo
+	if !hasAudio && !hasVideo {
+		writeError(w, r, http.StatusUnprocessableEntity, "hasAudio and hasVideo cannot both be false")
+		return
+	}
+	if len(body.Resolution) > 0 && !resolutionPattern.MatchString(body.Resolution) {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid resolution "+body.Resolution)
+		return
+	}
+	if body.FrameRate != 0 && (body.FrameRate < openvidu.MIN_FRAME_RATE || body.FrameRate > openvidu.MAX_FRAME_RATE) {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid frameRate")
+		return
+	}
+	if body.ShmSize != 0 && body.ShmSize < openvidu.MIN_SHM_SIZE {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid shmSize")
+		return
+	}
+
+	ses := s.sessions[body.Session]
+	if ses == nil {
+		writeError(w, r, http.StatusNotFound, "session "+body.Session+" not found")
+		return
+	}
+	if len(ses.connections) == 0 {
+		writeError(w, r, http.StatusNotAcceptable, "session "+body.Session+" has no connected participants")
+		return
+	}
+	if ses.properties.MediaMode == openvidu.RELAYED || len(ses.recording) > 0 {
+		writeError(w, r, http.StatusConflict, "session "+body.Session+" cannot be recorded")
+		return
+	}
+
+	rec := &recording{
+		Id:         ses.id,
+		SessionId:  ses.id,
+		Name:       body.Name,
+		OutputMode: openvidu.OutputMode(orDefault(string(body.OutputMode), string(ses.properties.DefaultOutputMode))),
+		HasAudio:   hasAudio,
+		HasVideo:   hasVideo,
+		CreatedAt:  now(),
+		Status:     s.RecordingStartStatus,
+	}
+	for i := 1; s.recordings[rec.Id] != nil; i++ {
+		rec.Id = fmt.Sprintf("%s~%d", ses.id, i)
+	}
+	if len(rec.Name) == 0 {
+		rec.Name = rec.Id
+	}
+	if rec.OutputMode == openvidu.COMPOSED && hasVideo {
+		rec.Resolution = orDefault(body.Resolution, "1920x1080")
+		rec.RecordingLayout = openvidu.RecordingLayout(orDefault(string(body.RecordingLayout), string(ses.properties.DefaultRecordingLayout)))
+		if rec.RecordingLayout == openvidu.CUSTOM {
+			rec.CustomLayout = body.CustomLayout
+		}
+		rec.FrameRate = body.FrameRate
+		if rec.FrameRate == 0 {
+			rec.FrameRate = 25
+		}
+		rec.ShmSize = body.ShmSize
+		if rec.ShmSize == 0 {
+			rec.ShmSize = 536870912
+		}
+	}
+	if rec.OutputMode == openvidu.INDIVIDUAL {
+		rec.IgnoreFailedStreams = body.IgnoreFailedStreams
+	}
+	rec.MediaNode = body.MediaNode
+
+	s.recordings[rec.Id] = rec
+	ses.recording = rec.Id
+	writeJson(w, http.StatusOK, rec)
+}
+
+func (s *Server) stopRecording(w http.ResponseWriter, r *http.Request, recordingId string) {
+	rec := s.recordings[recordingId]
+	if rec == nil {
+		writeError(w, r, http.StatusNotFound, "recording "+recordingId+" not found")
+		return
+	}
+	if rec.Status != openvidu.STARTED {
+		writeError(w, r, http.StatusNotAcceptable, "recording "+recordingId+" is "+string(rec.Status))
+		return
+	}
+
+	rec.Status = openvidu.STOPPED
+	rec.Duration = float64(now()-rec.CreatedAt) / 1000
+	if ses := s.sessions[rec.SessionId]; ses != nil && ses.recording == rec.Id {
+		ses.recording = ""
+	}
+	writeJson(w, http.StatusOK, rec)
+}
+
+func (s *Server) listRecordings(w http.ResponseWriter, r *http.Request) {
+	ids := make([]string, 0, len(s.recordings))
+	for id := range s.recordings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	items := make([]*recording, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, s.recordings[id])
+	}
+	writeJson(w, http.StatusOK, map[string]interface{}{"count": len(items), "items": items})
+}
+
+func (s *Server) getRecording(w http.ResponseWriter, r *http.Request, recordingId string) {
+	rec := s.recordings[recordingId]
+	if rec == nil {
+		writeError(w, r, http.StatusNotFound, "recording "+recordingId+" not found")
+		return
+	}
+	writeJson(w, http.StatusOK, rec)
+}
+
+func (s *Server) deleteRecording(w http.ResponseWriter, r *http.Request, recordingId string) {
+	rec := s.recordings[recordingId]
+	if rec == nil {
+		writeError(w, r, http.StatusNotFound, "recording "+recordingId+" not found")
+		return
+	}
+	if rec.Status == openvidu.STARTING || rec.Status == openvidu.STARTED {
+		writeError(w, r, http.StatusConflict, "recording "+recordingId+" is "+string(rec.Status))
+		return
+	}
+	delete(s.recordings, recordingId)
+	delete(s.media, recordingId)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveRecordingMedia(w http.ResponseWriter, r *http.Request, recordingId string, name string) {
+	rec := s.recordings[recordingId]
+	if rec == nil || rec.Status != openvidu.READY || rec.Name+rec.extension() != name {
+		writeError(w, r, http.StatusNotFound, "recording file "+recordingId+"/"+name+" not found")
+		return
+	}
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(s.media[recordingId]))
+}
+
+func (s *Server) newConnection(sessionId string, role openvidu.OpenViduRole, serverData string, clientData string) *connection {
+	createdAt := now()
+	return &connection{
+		id:             s.newId("con"),
+		sessionId:      sessionId,
+		connectionType: openvidu.WEBRTC,
+		status:         openvidu.ACTIVE,
+		createdAt:      createdAt,
+		activeAt:       createdAt,
+		record:         true,
+		role:           role,
+		serverData:     serverData,
+		clientData:     clientData,
+		location:       "unknown",
+		platform:       "openvidutest",
+		publishers:     make(map[string]*publisher),
+		subscribers:    make(map[string]*subscriber),
+	}
+}
+
+func (s *Server) newId(prefix string) string {
+	s.nextId++
+	if len(prefix) == 0 {
+		return fmt.Sprintf("%06d", s.nextId)
+	}
+	return fmt.Sprintf("%s_%06d", prefix, s.nextId)
+}
+
+func (s *Server) tokenUrl(sessionId string, tokenId string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(s.URL, "https://"), "http://")
+	return "wss://" + host + "?sessionId=" + sessionId + "&token=" + tokenId
+}
+
+func (ses *session) publisherConnection(streamId string) *connection {
+	for _, c := range ses.connections {
+		if c.publishers[streamId] != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+func (ses *session) removeConnection(connectionId string) {
+	c := ses.connections[connectionId]
+	delete(ses.connections, connectionId)
+	for streamId := range c.publishers {
+		ses.removeStream(streamId)
+	}
+}
+
+func (ses *session) removeStream(streamId string) {
+	for _, c := range ses.connections {
+		delete(c.publishers, streamId)
+		delete(c.subscribers, streamId)
+	}
+}
+
+func (ses *session) connectionsJson() []interface{} {
+	ids := make([]string, 0, len(ses.connections))
+	for id := range ses.connections {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	content := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		content = append(content, ses.connections[id].toJson())
+	}
+	return content
+}
+
+func (ses *session) toJson() map[string]interface{} {
+	content := ses.connectionsJson()
+	return map[string]interface{}{
+		"sessionId":              ses.id,
+		"createdAt":              ses.createdAt,
+		"mediaMode":              ses.properties.MediaMode,
+		"recordingMode":          ses.properties.RecordingMode,
+		"defaultOutputMode":      ses.properties.DefaultOutputMode,
+		"defaultRecordingLayout": ses.properties.DefaultRecordingLayout,
+		"defaultCustomLayout":    ses.properties.DefaultCustomLayout,
+		"customSessionId":        ses.properties.CustomSessionId,
+		"recording":              len(ses.recording) > 0,
+		"connections": map[string]interface{}{
+			"numberOfElements": len(content),
+			"content":          content,
+		},
+	}
+}
+
+func (c *connection) toJson() map[string]interface{} {
+	publishers := make([]*publisher, 0, len(c.publishers))
+	for _, p := range c.publishers {
+		publishers = append(publishers, p)
+	}
+	sort.Slice(publishers, func(i, j int) bool { return publishers[i].StreamId < publishers[j].StreamId })
+
+	subscribers := make([]*subscriber, 0, len(c.subscribers))
+	for _, sub := range c.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	sort.Slice(subscribers, func(i, j int) bool { return subscribers[i].StreamId < subscribers[j].StreamId })
+
+	var activeAt interface{}
+	if c.status == openvidu.ACTIVE {
+		activeAt = c.activeAt
+	}
+
+	content := map[string]interface{}{
+		"id":             c.id,
+		"object":         "connection",
+		"connectionId":   c.id,
+		"sessionId":      c.sessionId,
+		"type":           c.connectionType,
+		"status":         c.status,
+		"activeAt":       activeAt,
+		"record":         c.record,
+		"kurentoOptions": c.kurentoOptions,
+		"createdAt":      c.createdAt,
+		"location":       c.location,
+		"platform":       c.platform,
+		"token":          c.token,
+		"role":           c.role,
+		"serverData":     c.serverData,
+		"clientData":     c.clientData,
+		"publishers":     publishers,
+		"subscribers":    subscribers,
+	}
+	if c.connectionType == openvidu.IPCAM {
+		content["rtspUri"] = c.rtspUri
+		content["adaptativeBitrate"] = c.adaptativeBitrate
+		content["onlyPlayWithSubscribers"] = c.onlyPlayWithSubscribers
+		content["networkCache"] = c.networkCache
+	}
+	return content
+}
+
+func (r *recording) extension() string {
+	if r.OutputMode == openvidu.INDIVIDUAL {
+		return ".zip"
+	}
+	if !r.HasVideo {
+		return ".webm"
+	}
+	return ".mp4"
+}
+
+// decode reads the JSON body into the struct pointed to by v. Unlike
+// encoding/json, field names must match the json tags of v exactly, so that
+// the server rejects misnamed fields instead of hiding client bugs.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid body: "+err.Error())
+		return false
+	}
+
+	fields := jsonFields(reflect.TypeOf(v).Elem())
+	for name := range raw {
+		if !fields[name] {
+			writeError(w, r, http.StatusBadRequest, "invalid body: unknown field "+name)
+			return false
+		}
+	}
+
+	b, _ := json.Marshal(raw)
+	if err := json.Unmarshal(b, v); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if len(name) > 0 && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	writeJson(w, status, map[string]interface{}{
+		"timestamp": now(),
+		"status":    status,
+		"error":     http.StatusText(status),
+		"message":   message,
+		"path":      r.URL.Path,
+	})
+}
+
+func validRole(role openvidu.OpenViduRole) bool {
+	switch role {
+	case openvidu.SUBSCRIBER, openvidu.PUBLISHER, openvidu.MODERATOR:
+		return true
+	}
+	return false
+}
+
+func orDefault(value string, def string) string {
+	if len(value) == 0 {
+		return def
+	}
+	return value
+}
+
+func now() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,211 @@
+package openvidutest
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+)
+
+func TestClient(t *testing.T) {
+	srv := NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := session.GenerateToken(&openvidu.TokenOptions{Role: openvidu.PUBLISHER, Data: "server"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.SessionId != session.SessionId {
+		t.Errorf("token session = %q, want %q", token.SessionId, session.SessionId)
+	}
+
+	connectionId, err := srv.Join(token.Token, "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamId, err := srv.Publish(session.SessionId, connectionId, &MediaOptions{HasAudio: true, HasVideo: true, VideoDimensions: `{"width":640,"height":480}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := session.Fetch(); err != nil {
+		t.Fatal(err)
+	}
+	c := session.GetActiveConnection(connectionId)
+	if c == nil || c.ServerData != "server" || c.ClientData != "client" || c.Publishers[streamId] == nil {
+		t.Fatalf("connection = %+v", c)
+	}
+
+	rec, err := ov.StartRecordingById(session.SessionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Status != openvidu.STARTED || !session.IsRecording() {
+		t.Errorf("status = %s, recording = %v", rec.Status, session.IsRecording())
+	}
+	if _, err := ov.StartRecordingById(session.SessionId); !errors.Is(err, openvidu.ErrRecordingAlreadyStarted) {
+		t.Errorf("second start: err = %v, want ErrRecordingAlreadyStarted", err)
+	}
+
+	rec, err = ov.StopRecording(rec.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Status != openvidu.STOPPED {
+		t.Errorf("status = %s, want STOPPED", rec.Status)
+	}
+	var buf bytes.Buffer
+	if _, err := ov.DownloadRecording(rec.Id, &buf); !errors.Is(err, openvidu.ErrRecordingNotReady) {
+		t.Errorf("download before READY: err = %v, want ErrRecordingNotReady", err)
+	}
+
+	media := []byte("recorded media")
+	if err := srv.SetRecordingMedia(rec.Id, media); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.SetRecordingStatus(rec.Id, openvidu.READY); err != nil {
+		t.Fatal(err)
+	}
+	n, err := ov.DownloadRecording(rec.Id, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(media)) || !bytes.Equal(buf.Bytes(), media) {
+		t.Errorf("downloaded %q, want %q", buf.Bytes(), media)
+	}
+
+	if err := ov.DeleteRecording(rec.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ov.GetRecording(rec.Id); !errors.Is(err, openvidu.ErrRecordingNotFound) {
+		t.Errorf("err = %v, want ErrRecordingNotFound", err)
+	}
+
+	if err := session.ForceDisconnectById(connectionId); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.Fetch(); !errors.Is(err, openvidu.ErrSessionNotFound) {
+		t.Errorf("err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestConnections(t *testing.T) {
+	srv := NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bandwidth := int32(1000)
+	c, err := session.CreateConnection(&openvidu.ConnectionOptions{
+		Type:           openvidu.WEBRTC,
+		Data:           "server",
+		Role:           openvidu.SUBSCRIBER,
+		KurentoOptions: &openvidu.KurentoOptions{VideoMaxRecvBandwidth: &bandwidth},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != openvidu.PENDING || c.Role != openvidu.SUBSCRIBER {
+		t.Errorf("connection = %+v", c)
+	}
+
+	record := false
+	c, err = session.UpdateConnection(c.ConnectionId, &openvidu.ConnectionOptions{Role: openvidu.MODERATOR, Record: &record})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Role != openvidu.MODERATOR || c.Record {
+		t.Errorf("connection = %+v", c)
+	}
+
+	if _, err := srv.Join(c.Token, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := session.SignalById("chat", "hello", c.ConnectionId); err != nil {
+		t.Fatal(err)
+	}
+	if signals := srv.Signals(); len(signals) != 1 || signals[0].Type != "chat" || signals[0].Data != "hello" {
+		t.Errorf("signals = %+v", signals)
+	}
+
+	camera, err := session.PublishIPCamera("rtsp://camera.local/stream", &openvidu.IPCameraOptions{Data: "camera"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	connections, err := session.ListConnections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(connections) != 2 {
+		t.Errorf("connections = %d, want 2", len(connections))
+	}
+	if _, err := session.GetConnection(camera.ConnectionId); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := NewServer("secret")
+	defer srv.Close()
+
+	ov := openvidu.NewOpenVidu(srv.URL, "wrong")
+	if _, err := ov.CreateSession0(); !errors.Is(err, openvidu.ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	srv := NewServer("secret")
+	defer srv.Close()
+	if _, err := srv.Client().CreateSession1(&openvidu.SessionProperties{
+		MediaMode:              openvidu.ROUTED,
+		RecordingMode:          openvidu.MANUAL,
+		DefaultOutputMode:      openvidu.COMPOSED,
+		DefaultRecordingLayout: openvidu.BEST_FIT,
+		CustomSessionId:        "ses",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"field case", openvidu.API_SESSIONS, `{"MediaMode":"ROUTED"}`},
+		{"unknown field", openvidu.API_TOKENS, `{"session":"ses","sessionId":"ses"}`},
+		{"legacy recording session", openvidu.API_RECORDINGS + openvidu.API_RECORDINGS_START, `{"sessionId":"ses"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, srv.URL+"/"+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.SetBasicAuth("OPENVIDUAPP", "secret")
+			req.Header.Set("Content-Type", "application/json")
+			response, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+			if response.StatusCode != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", response.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package openvidu
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	DEFAULT_TIMEOUT    = 30 * time.Second
+	DEFAULT_USER_AGENT = "openvidu-go-client"
+)
+
+// ErrTransportNotConfigurable is returned by every call of a client whose
+// TLS options cannot be applied, because the http.Client given with
+// WithHTTPClient uses a transport other than *http.Transport.
+var ErrTransportNotConfigurable = errors.New("openvidu: TLS options require the client transport to be an *http.Transport")
+
+// Option configures an OpenVidu client created by NewOpenVidu.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	httpClient    *http.Client
+	transport     http.RoundTripper
+	tlsConfig     *tls.Config
+	rootCAs       *x509.CertPool
+	insecure      bool
+	timeout       time.Duration
+	timeoutSet    bool
+	userAgent     string
+	headers       http.Header
+	retryPolicy   *RetryPolicy
+	middlewares   []Middleware
+	serverVersion string
+}
+
+// WithHTTPClient makes the client send every request through c. The
+// supplied client is copied, never modified.
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = c
+	}
+}
+
+// WithTransport replaces the transport of the underlying http.Client, e.g.
+// with an instrumented http.RoundTripper.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = rt
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the transport built by the
+// client. It is ignored when WithTransport is used. The TLS options cannot
+// be combined with a WithHTTPClient client whose transport is not an
+// *http.Transport, as that would drop the caller's transport: every call of
+// such a client fails with ErrTransportNotConfigurable.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *clientOptions) {
+		o.tlsConfig = config
+	}
+}
+
+// WithRootCAs verifies the OpenVidu server certificate against pool instead
+// of the system roots. It is ignored when WithTransport is used and has the
+// same restriction as WithTLSConfig.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(o *clientOptions) {
+		o.rootCAs = pool
+	}
+}
+
+// WithInsecureSkipVerify disables verification of the OpenVidu server
+// certificate. Only use it against development servers with self-signed
+// certificates. It has the same restriction as WithTLSConfig.
+func WithInsecureSkipVerify() Option {
+	return func(o *clientOptions) {
+		o.insecure = true
+	}
+}
+
+// WithTimeout limits the time spent on a single request, including reading
+// the response body. A zero duration means no timeout. It defaults to
+// DEFAULT_TIMEOUT, except for clients given with WithHTTPClient, which keep
+// their own Timeout. Downloads of recording media are not limited by it,
+// only by their context.
+func WithTimeout(d time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = d
+		o.timeoutSet = true
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithHeader adds an extra header sent with every request.
+func WithHeader(key string, value string) Option {
+	return func(o *clientOptions) {
+		o.headers.Add(key, value)
+	}
+}
+
+func newClientOptions(opts []Option) *clientOptions {
+	co := &clientOptions{
+		userAgent: DEFAULT_USER_AGENT,
+		headers:   make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(co)
+	}
+	return co
+}
+
+func (co *clientOptions) buildHttpClient() (*http.Client, error) {
+	client := &http.Client{}
+	if co.httpClient != nil {
+		c := *co.httpClient
+		client = &c
+	}
+
+	if co.transport != nil {
+		client.Transport = co.transport
+	} else if co.tlsConfig != nil || co.rootCAs != nil || co.insecure {
+		var base *http.Transport
+		switch t := client.Transport.(type) {
+		case nil:
+			base = http.DefaultTransport.(*http.Transport)
+		case *http.Transport:
+			base = t
+		default:
+			return nil, ErrTransportNotConfigurable
+		}
+
+		tr := base.Clone()
+		var config *tls.Config
+		if co.tlsConfig != nil {
+			config = co.tlsConfig.Clone()
+		} else if tr.TLSClientConfig != nil {
+			config = tr.TLSClientConfig.Clone()
+		} else {
+			config = &tls.Config{}
+		}
+		if co.rootCAs != nil {
+			config.RootCAs = co.rootCAs
+		}
+		if co.insecure {
+			config.InsecureSkipVerify = true
+		}
+		tr.TLSClientConfig = config
+		client.Transport = tr
+	}
+
+	if co.timeoutSet {
+		client.Timeout = co.timeout
+	} else if co.httpClient == nil {
+		client.Timeout = DEFAULT_TIMEOUT
+	}
+	return client, nil
+}
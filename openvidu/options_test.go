@@ -0,0 +1,67 @@
+package openvidu
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want time.Duration
+	}{
+		{"default", nil, DEFAULT_TIMEOUT},
+		{"option", []Option{WithTimeout(time.Second)}, time.Second},
+		{"http client", []Option{WithHTTPClient(&http.Client{Timeout: time.Minute})}, time.Minute},
+		{"http client without timeout", []Option{WithHTTPClient(&http.Client{})}, 0},
+		{"http client and option", []Option{WithHTTPClient(&http.Client{Timeout: time.Minute}), WithTimeout(time.Second)}, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ov := NewOpenVidu("https://localhost", "secret", tt.opts...)
+			if ov.err != nil {
+				t.Fatal(ov.err)
+			}
+			if ov.httpClient.Timeout != tt.want {
+				t.Errorf("Timeout = %v, want %v", ov.httpClient.Timeout, tt.want)
+			}
+		})
+	}
+}
+
+func TestTLSOptionsKeepTransport(t *testing.T) {
+	base := &http.Transport{}
+	ov := NewOpenVidu("https://localhost", "secret", WithHTTPClient(&http.Client{Transport: base}), WithRootCAs(x509.NewCertPool()))
+	tr, ok := ov.httpClient.Transport.(*http.Transport)
+	if !ok || tr == base || tr.TLSClientConfig == nil || tr.TLSClientConfig.RootCAs == nil {
+		t.Fatalf("transport not configured: %#v", ov.httpClient.Transport)
+	}
+}
+
+func TestTLSOptionsRefuseCustomTransport(t *testing.T) {
+	called := false
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		called = true
+		return nil, errors.New("unexpected call")
+	})
+	ov := NewOpenVidu("https://localhost", "secret", WithHTTPClient(&http.Client{Transport: rt}), WithInsecureSkipVerify())
+
+	_, err := ov.FetchContext(context.Background())
+	if !errors.Is(err, ErrTransportNotConfigurable) {
+		t.Fatalf("err = %v, want ErrTransportNotConfigurable", err)
+	}
+	if called {
+		t.Error("request sent through a transport the options could not configure")
+	}
+}
@@ -11,3 +11,9 @@ type Publisher struct {
 	TypeOfVideo     string		`json:"typeOfVideo"`
 	VideoDimensions string		`json:"videoDimensions"`
 }
+
+// Dimensions parses VideoDimensions, which holds the raw value sent by the
+// server. It fails for publishers without video.
+func (p *Publisher) Dimensions() (Resolution, error) {
+	return ParseVideoDimensions(p.VideoDimensions)
+}
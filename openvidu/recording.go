@@ -1,5 +1,7 @@
 package openvidu
 
+import "encoding/json"
+
 type Recording struct {
 	Status              RecordingStatus
 	Id                  string
@@ -26,6 +28,45 @@ type recordingJson struct {
 	Resolution      string          `json:"resolution"`
 	RecordingLayout RecordingLayout `json:"recordingLayout"`
 	CustomLayout    string          `json:"customLayout"`
+
+	FrameRate           int             `json:"frameRate"`
+	ShmSize             int64           `json:"shmSize"`
+	IgnoreFailedStreams bool            `json:"ignoreFailedStreams"`
+	MediaNode           json.RawMessage `json:"mediaNode"`
+}
+
+type recordingRequest struct {
+	Session         string          `json:"session"`
+	Name            string          `json:"name,omitempty"`
+	OutputMode      OutputMode      `json:"outputMode,omitempty"`
+	HasAudio        bool            `json:"hasAudio"`
+	HasVideo        bool            `json:"hasVideo"`
+	Resolution      string          `json:"resolution,omitempty"`
+	RecordingLayout RecordingLayout `json:"recordingLayout,omitempty"`
+	CustomLayout    string          `json:"customLayout,omitempty"`
+
+	FrameRate           int            `json:"frameRate,omitempty"`
+	ShmSize             int64          `json:"shmSize,omitempty"`
+	IgnoreFailedStreams bool           `json:"ignoreFailedStreams,omitempty"`
+	MediaNode           *mediaNodeJson `json:"mediaNode,omitempty"`
+}
+
+type mediaNodeJson struct {
+	Id string `json:"id"`
+}
+
+// parseMediaNode returns the id of a media node, sent either as an object
+// or as a plain id.
+func parseMediaNode(raw json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+	var node mediaNodeJson
+	if err := json.Unmarshal(raw, &node); err == nil {
+		return node.Id
+	}
+	return ""
 }
 
 func NewRecording(rj *recordingJson) *Recording {
@@ -46,6 +87,9 @@ func NewRecording(rj *recordingJson) *Recording {
 		HasAudio:   rj.HasAudio,
 		HasVideo:   rj.HasVideo,
 	}
+	if len(rj.MediaNode) > 0 {
+		rp.MediaNode = parseMediaNode(rj.MediaNode)
+	}
 
 	if outputMode == COMPOSED && rj.HasVideo {
 		rp.Resolution = rj.Resolution
@@ -53,6 +97,11 @@ func NewRecording(rj *recordingJson) *Recording {
 		if len(rj.CustomLayout) > 0 {
 			rp.CustomLayout = rj.CustomLayout
 		}
+		rp.FrameRate = rj.FrameRate
+		rp.ShmSize = rj.ShmSize
+	}
+	if outputMode == INDIVIDUAL {
+		rp.IgnoreFailedStreams = rj.IgnoreFailedStreams
 	}
 
 	r.RecordingProperties = rp.Build()
@@ -0,0 +1,71 @@
+package openvidu
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Width and height limits of the resolutions accepted by OpenVidu for
+// COMPOSED recordings.
+const (
+	MIN_RESOLUTION_SIDE = 100
+	MAX_RESOLUTION_SIDE = 1999
+)
+
+// Resolution is a size in pixels, as used for the resolution of recordings
+// and the dimensions of published videos.
+type Resolution struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+var resolutionPattern = regexp.MustCompile(`^(\d+)x(\d+)$`)
+
+// ParseResolution parses a resolution formatted as "WIDTHxHEIGHT", like
+// RecordingProperties.Resolution. Both the width and the height must be
+// positive.
+func ParseResolution(s string) (Resolution, error) {
+	m := resolutionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Resolution{}, fmt.Errorf("openvidu: invalid resolution %q", s)
+	}
+	width, err := strconv.Atoi(m[1])
+	if err != nil {
+		return Resolution{}, fmt.Errorf("openvidu: invalid resolution %q", s)
+	}
+	height, err := strconv.Atoi(m[2])
+	if err != nil || width <= 0 || height <= 0 {
+		return Resolution{}, fmt.Errorf("openvidu: invalid resolution %q", s)
+	}
+	return Resolution{Width: width, Height: height}, nil
+}
+
+// ParseVideoDimensions parses video dimensions formatted as a JSON object,
+// like Publisher.VideoDimensions. Both the width and the height must be
+// positive.
+func ParseVideoDimensions(s string) (Resolution, error) {
+	var r Resolution
+	if err := json.Unmarshal([]byte(s), &r); err != nil || r.Width <= 0 || r.Height <= 0 {
+		return Resolution{}, fmt.Errorf("openvidu: invalid video dimensions %q", s)
+	}
+	return r, nil
+}
+
+// String formats r as "WIDTHxHEIGHT".
+func (r Resolution) String() string {
+	return strconv.Itoa(r.Width) + "x" + strconv.Itoa(r.Height)
+}
+
+// VideoDimensions formats r as a JSON object, as in Publisher.VideoDimensions.
+func (r Resolution) VideoDimensions() string {
+	return `{"width":` + strconv.Itoa(r.Width) + `,"height":` + strconv.Itoa(r.Height) + `}`
+}
+
+// Recordable tells whether OpenVidu accepts r as the resolution of a
+// recording.
+func (r Resolution) Recordable() bool {
+	return r.Width >= MIN_RESOLUTION_SIDE && r.Width <= MAX_RESOLUTION_SIDE &&
+		r.Height >= MIN_RESOLUTION_SIDE && r.Height <= MAX_RESOLUTION_SIDE
+}
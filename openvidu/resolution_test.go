@@ -0,0 +1,61 @@
+package openvidu_test
+
+import (
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+)
+
+func TestParseResolution(t *testing.T) {
+	tests := []struct {
+		s     string
+		want  openvidu.Resolution
+		valid bool
+	}{
+		{"1920x1080", openvidu.Resolution{Width: 1920, Height: 1080}, true},
+		{"640x480", openvidu.Resolution{Width: 640, Height: 480}, true},
+		{"0x480", openvidu.Resolution{}, false},
+		{"640x0", openvidu.Resolution{}, false},
+		{"640", openvidu.Resolution{}, false},
+		{"-640x480", openvidu.Resolution{}, false},
+		{"", openvidu.Resolution{}, false},
+	}
+	for _, tt := range tests {
+		r, err := openvidu.ParseResolution(tt.s)
+		if (err == nil) != tt.valid || r != tt.want {
+			t.Errorf("ParseResolution(%q) = %v, %v", tt.s, r, err)
+		}
+		if tt.valid && r.String() != tt.s {
+			t.Errorf("String() = %q, want %q", r.String(), tt.s)
+		}
+	}
+}
+
+func TestParseVideoDimensions(t *testing.T) {
+	tests := []struct {
+		s     string
+		want  openvidu.Resolution
+		valid bool
+	}{
+		{`{"width":640,"height":480}`, openvidu.Resolution{Width: 640, Height: 480}, true},
+		{"", openvidu.Resolution{}, false},
+		{"null", openvidu.Resolution{}, false},
+		{"{}", openvidu.Resolution{}, false},
+		{`{"width":-5,"height":0}`, openvidu.Resolution{}, false},
+		{`{"width":640}`, openvidu.Resolution{}, false},
+	}
+	for _, tt := range tests {
+		r, err := openvidu.ParseVideoDimensions(tt.s)
+		if (err == nil) != tt.valid || r != tt.want {
+			t.Errorf("ParseVideoDimensions(%q) = %v, %v", tt.s, r, err)
+		}
+		if tt.valid && r.VideoDimensions() != tt.s {
+			t.Errorf("VideoDimensions() = %q, want %q", r.VideoDimensions(), tt.s)
+		}
+	}
+
+	p := &openvidu.Publisher{HasAudio: true}
+	if _, err := p.Dimensions(); err == nil {
+		t.Error("Dimensions() succeeded for a publisher without video")
+	}
+}
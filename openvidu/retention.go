@@ -0,0 +1,215 @@
+package openvidu
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"time"
+)
+
+type RetentionReason string
+
+const (
+	// Reasons for deleting a recording.
+	RETENTION_MAX_AGE        RetentionReason = "maxAge"
+	RETENTION_MAX_TOTAL_SIZE RetentionReason = "maxTotalSize"
+
+	// Reasons for keeping a recording.
+	RETENTION_IN_PROGRESS   RetentionReason = "inProgress"
+	RETENTION_KEEP_LAST     RetentionReason = "keepLastPerSession"
+	RETENTION_KEEP_NAME     RetentionReason = "keepNamePattern"
+	RETENTION_WITHIN_LIMITS RetentionReason = "withinLimits"
+)
+
+// RetentionPolicy decides which recordings to delete. Zero fields disable
+// their rule. Recordings still STARTING or STARTED are never deleted, nor
+// are those kept by KeepLastPerSession or KeepNamePattern.
+type RetentionPolicy struct {
+	// MaxAge deletes the recordings created more than MaxAge ago.
+	MaxAge time.Duration
+
+	// MaxTotalSize deletes the oldest recordings until the recordings kept
+	// add up to at most MaxTotalSize bytes.
+	MaxTotalSize int64
+
+	// KeepLastPerSession keeps the given number of most recent finished
+	// recordings of every session.
+	KeepLastPerSession int
+
+	// KeepNamePattern keeps the recordings with a matching name.
+	KeepNamePattern *regexp.Regexp
+
+	// DeleteInterval is the delay between two deletions made by
+	// ApplyRetentionPolicy, to spare the server.
+	DeleteInterval time.Duration
+}
+
+// RetentionDecision is the fate of one recording in a RetentionPlan.
+type RetentionDecision struct {
+	Recording *Recording
+	Reason    RetentionReason
+}
+
+// RetentionPlan lists the recordings a RetentionPolicy deletes and keeps,
+// oldest first. Computing a plan deletes nothing, so it doubles as a dry
+// run.
+type RetentionPlan struct {
+	Delete []*RetentionDecision
+	Keep   []*RetentionDecision
+}
+
+// RecordingIds returns the ids of the recordings to delete.
+func (p *RetentionPlan) RecordingIds() []string {
+	ids := make([]string, 0, len(p.Delete))
+	for _, d := range p.Delete {
+		ids = append(ids, d.Recording.Id)
+	}
+	return ids
+}
+
+// RetentionResult reports the deletions made for a RetentionPlan. Failed
+// maps the ids of the recordings that could not be deleted to the error.
+type RetentionResult struct {
+	Deleted []string
+	Failed  map[string]error
+}
+
+var errNoRetentionPolicy = errors.New("openvidu: no retention policy given")
+
+// Plan decides the fate of recordings at the given time. It fails on a nil
+// policy.
+func (rp *RetentionPolicy) Plan(recordings []*Recording, now time.Time) (*RetentionPlan, error) {
+	if rp == nil {
+		return nil, errNoRetentionPolicy
+	}
+
+	sorted := append([]*Recording(nil), recordings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt < sorted[j].CreatedAt
+	})
+
+	reasons := make(map[*Recording]RetentionReason, len(sorted))
+	inProgress := func(r *Recording) bool {
+		return r.Status == STARTING || r.Status == STARTED
+	}
+
+	// Protected recordings, newest first for KeepLastPerSession.
+	kept := make(map[string]int)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		r := sorted[i]
+		switch {
+		case inProgress(r):
+			reasons[r] = RETENTION_IN_PROGRESS
+		case rp.KeepNamePattern != nil && rp.KeepNamePattern.MatchString(r.Name()):
+			reasons[r] = RETENTION_KEEP_NAME
+		case kept[r.SessionId] < rp.KeepLastPerSession:
+			kept[r.SessionId]++
+			reasons[r] = RETENTION_KEEP_LAST
+		}
+	}
+
+	if rp.MaxAge > 0 {
+		oldest := now.Add(-rp.MaxAge).UnixNano() / int64(time.Millisecond)
+		for _, r := range sorted {
+			if len(reasons[r]) == 0 && r.CreatedAt < oldest {
+				reasons[r] = RETENTION_MAX_AGE
+			}
+		}
+	}
+
+	if rp.MaxTotalSize > 0 {
+		var total int64
+		for _, r := range sorted {
+			if reasons[r] != RETENTION_MAX_AGE {
+				total += r.Size
+			}
+		}
+		for _, r := range sorted {
+			if total <= rp.MaxTotalSize {
+				break
+			}
+			if len(reasons[r]) == 0 {
+				reasons[r] = RETENTION_MAX_TOTAL_SIZE
+				total -= r.Size
+			}
+		}
+	}
+
+	plan := &RetentionPlan{}
+	for _, r := range sorted {
+		switch reasons[r] {
+		case RETENTION_MAX_AGE, RETENTION_MAX_TOTAL_SIZE:
+			plan.Delete = append(plan.Delete, &RetentionDecision{Recording: r, Reason: reasons[r]})
+		case "":
+			plan.Keep = append(plan.Keep, &RetentionDecision{Recording: r, Reason: RETENTION_WITHIN_LIMITS})
+		default:
+			plan.Keep = append(plan.Keep, &RetentionDecision{Recording: r, Reason: reasons[r]})
+		}
+	}
+	return plan, nil
+}
+
+// PlanRetention lists the recordings of the server and plans their
+// retention, without deleting any.
+func (o *OpenVidu) PlanRetention(policy *RetentionPolicy) (*RetentionPlan, error) {
+	return o.PlanRetentionContext(context.Background(), policy)
+}
+
+func (o *OpenVidu) PlanRetentionContext(ctx context.Context, policy *RetentionPolicy) (*RetentionPlan, error) {
+	if policy == nil {
+		return nil, errNoRetentionPolicy
+	}
+	recordings, err := o.ListRecordingContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return policy.Plan(recordings, time.Now())
+}
+
+// ApplyRetentionPolicy plans the retention of the recordings of the server
+// and executes the plan, waiting policy.DeleteInterval between deletions.
+func (o *OpenVidu) ApplyRetentionPolicy(policy *RetentionPolicy) (*RetentionPlan, *RetentionResult, error) {
+	return o.ApplyRetentionPolicyContext(context.Background(), policy)
+}
+
+func (o *OpenVidu) ApplyRetentionPolicyContext(ctx context.Context, policy *RetentionPolicy) (*RetentionPlan, *RetentionResult, error) {
+	plan, err := o.PlanRetentionContext(ctx, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := o.ExecuteRetentionPlanContext(ctx, plan, policy.DeleteInterval)
+	return plan, result, err
+}
+
+// ExecuteRetentionPlan deletes the recordings of plan, waiting
+// deleteInterval between deletions. Recordings already deleted count as
+// deleted and failed deletions do not stop the execution, which only ends
+// early when ctx is done.
+func (o *OpenVidu) ExecuteRetentionPlan(plan *RetentionPlan, deleteInterval time.Duration) (*RetentionResult, error) {
+	return o.ExecuteRetentionPlanContext(context.Background(), plan, deleteInterval)
+}
+
+func (o *OpenVidu) ExecuteRetentionPlanContext(ctx context.Context, plan *RetentionPlan, deleteInterval time.Duration) (*RetentionResult, error) {
+	result := &RetentionResult{
+		Failed: make(map[string]error),
+	}
+	for i, d := range plan.Delete {
+		if i > 0 {
+			if err := sleepContext(ctx, deleteInterval); err != nil {
+				return result, err
+			}
+		}
+
+		err := o.DeleteRecordingContext(ctx, d.Recording.Id)
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if err != nil && !errors.Is(err, ErrRecordingNotFound) {
+			result.Failed[d.Recording.Id] = err
+			continue
+		}
+		result.Deleted = append(result.Deleted, d.Recording.Id)
+	}
+	return result, nil
+}
@@ -0,0 +1,248 @@
+package openvidu_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+)
+
+func TestRetentionPlan(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	rec := func(id string, sessionId string, status openvidu.RecordingStatus, age time.Duration, size int64) *openvidu.Recording {
+		return &openvidu.Recording{
+			Id:                  id,
+			SessionId:           sessionId,
+			Status:              status,
+			CreatedAt:           now.Add(-age).UnixNano() / int64(time.Millisecond),
+			Size:                size,
+			RecordingProperties: &openvidu.RecordingProperties{Name: id},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		policy     openvidu.RetentionPolicy
+		recordings []*openvidu.Recording
+		// The decisions, oldest first, as "id reason".
+		delete []string
+		keep   []string
+	}{
+		{
+			"no rules",
+			openvidu.RetentionPolicy{},
+			[]*openvidu.Recording{
+				rec("new", "ses_a", openvidu.READY, time.Hour, 10),
+				rec("old", "ses_a", openvidu.READY, 1000*time.Hour, 10),
+			},
+			nil,
+			[]string{"old withinLimits", "new withinLimits"},
+		},
+		{
+			"max age",
+			openvidu.RetentionPolicy{MaxAge: 24 * time.Hour},
+			[]*openvidu.Recording{
+				rec("recent", "ses_a", openvidu.READY, time.Hour, 10),
+				rec("old", "ses_a", openvidu.READY, 48*time.Hour, 10),
+				rec("failed", "ses_b", openvidu.FAILED, 25*time.Hour, 0),
+				rec("limit", "ses_b", openvidu.STOPPED, 24*time.Hour, 10),
+			},
+			[]string{"old maxAge", "failed maxAge"},
+			[]string{"limit withinLimits", "recent withinLimits"},
+		},
+		{
+			"max total size",
+			openvidu.RetentionPolicy{MaxTotalSize: 100},
+			[]*openvidu.Recording{
+				rec("r1", "ses_a", openvidu.READY, 4*time.Hour, 50),
+				rec("r2", "ses_a", openvidu.READY, 3*time.Hour, 40),
+				rec("r3", "ses_b", openvidu.READY, 2*time.Hour, 30),
+				rec("r4", "ses_b", openvidu.READY, time.Hour, 30),
+			},
+			[]string{"r1 maxTotalSize"},
+			[]string{"r2 withinLimits", "r3 withinLimits", "r4 withinLimits"},
+		},
+		{
+			"max total size skips recordings too old",
+			openvidu.RetentionPolicy{MaxAge: 24 * time.Hour, MaxTotalSize: 100},
+			[]*openvidu.Recording{
+				rec("ancient", "ses_a", openvidu.READY, 72*time.Hour, 500),
+				rec("old", "ses_a", openvidu.READY, 48*time.Hour, 80),
+				rec("r1", "ses_b", openvidu.READY, 12*time.Hour, 60),
+				rec("r2", "ses_b", openvidu.READY, time.Hour, 50),
+			},
+			[]string{"ancient maxAge", "old maxAge", "r1 maxTotalSize"},
+			[]string{"r2 withinLimits"},
+		},
+		{
+			"keep last per session",
+			openvidu.RetentionPolicy{MaxAge: time.Hour, KeepLastPerSession: 1},
+			[]*openvidu.Recording{
+				rec("a1", "ses_a", openvidu.READY, 10*time.Hour, 10),
+				rec("a2", "ses_a", openvidu.READY, 5*time.Hour, 10),
+				rec("b1", "ses_b", openvidu.READY, 10*time.Hour, 10),
+				rec("b2", "ses_b", openvidu.STARTED, 2*time.Hour, 0),
+			},
+			[]string{"a1 maxAge"},
+			[]string{"b1 keepLastPerSession", "a2 keepLastPerSession", "b2 inProgress"},
+		},
+		{
+			"keep name pattern",
+			openvidu.RetentionPolicy{MaxAge: time.Hour, MaxTotalSize: 10, KeepNamePattern: regexp.MustCompile(`^keep-`)},
+			[]*openvidu.Recording{
+				rec("keep-1", "ses_a", openvidu.READY, 10*time.Hour, 100),
+				rec("other", "ses_a", openvidu.READY, 9*time.Hour, 100),
+				rec("keep-2", "ses_a", openvidu.READY, time.Minute, 100),
+			},
+			[]string{"other maxAge"},
+			[]string{"keep-1 keepNamePattern", "keep-2 keepNamePattern"},
+		},
+		{
+			"in progress",
+			openvidu.RetentionPolicy{MaxAge: time.Hour, MaxTotalSize: 10, KeepLastPerSession: 1},
+			[]*openvidu.Recording{
+				rec("starting", "ses_a", openvidu.STARTING, 10*time.Hour, 100),
+				rec("started", "ses_a", openvidu.STARTED, 9*time.Hour, 100),
+				rec("stopped", "ses_b", openvidu.STOPPED, 8*time.Hour, 100),
+				rec("ready", "ses_b", openvidu.READY, time.Minute, 100),
+			},
+			[]string{"stopped maxAge"},
+			[]string{"starting inProgress", "started inProgress", "ready keepLastPerSession"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := tt.policy.Plan(tt.recordings, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := decisions(plan.Delete); !reflect.DeepEqual(got, tt.delete) {
+				t.Errorf("delete = %v, want %v", got, tt.delete)
+			}
+			if got := decisions(plan.Keep); !reflect.DeepEqual(got, tt.keep) {
+				t.Errorf("keep = %v, want %v", got, tt.keep)
+			}
+		})
+	}
+}
+
+func decisions(ds []*openvidu.RetentionDecision) []string {
+	var s []string
+	for _, d := range ds {
+		s = append(s, d.Recording.Id+" "+string(d.Reason))
+	}
+	return s
+}
+
+func TestNilRetentionPolicy(t *testing.T) {
+	srv := newRecordingServer(t)
+	defer srv.Close()
+	ov := srv.Client()
+
+	var policy *openvidu.RetentionPolicy
+	if _, err := policy.Plan(nil, time.Now()); err == nil {
+		t.Error("Plan accepted a nil policy")
+	}
+	if _, err := ov.PlanRetention(nil); err == nil {
+		t.Error("PlanRetention accepted a nil policy")
+	}
+	if _, _, err := ov.ApplyRetentionPolicy(nil); err == nil {
+		t.Error("ApplyRetentionPolicy accepted a nil policy")
+	}
+	if recordings, err := ov.ListRecording(); err != nil || len(recordings) != len(recordingFixtures) {
+		t.Errorf("recordings = %d, %v, want %d", len(recordings), err, len(recordingFixtures))
+	}
+}
+
+// recordingsByName lists the recordings of the server by name.
+func recordingsByName(t *testing.T, ov *openvidu.OpenVidu) map[string]*openvidu.Recording {
+	t.Helper()
+	recordings, err := ov.ListRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]*openvidu.Recording)
+	for _, r := range recordings {
+		byName[r.Name()] = r
+	}
+	return byName
+}
+
+func TestExecuteRetentionPlan(t *testing.T) {
+	srv := newRecordingServer(t)
+	defer srv.Close()
+	ov := srv.Client()
+
+	byName := recordingsByName(t, ov)
+	plan := &openvidu.RetentionPlan{}
+	for _, name := range []string{"a-1", "b-2", "c-1"} {
+		plan.Delete = append(plan.Delete, &openvidu.RetentionDecision{Recording: byName[name], Reason: openvidu.RETENTION_MAX_AGE})
+	}
+	// Already deleted recordings count as deleted.
+	if err := ov.DeleteRecording(byName["a-1"].Id); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ov.ExecuteRetentionPlan(plan, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{byName["a-1"].Id, byName["c-1"].Id}; !reflect.DeepEqual(result.Deleted, want) {
+		t.Errorf("deleted = %v, want %v", result.Deleted, want)
+	}
+	if len(result.Failed) != 1 || result.Failed[byName["b-2"].Id] == nil {
+		t.Errorf("failed = %v, want the STARTED recording", result.Failed)
+	}
+	left := recordingsByName(t, ov)
+	if len(left) != 3 || left["a-2"] == nil || left["b-1"] == nil || left["b-2"] == nil {
+		t.Errorf("recordings left = %v", left)
+	}
+}
+
+func TestExecuteRetentionPlanCancel(t *testing.T) {
+	srv := newRecordingServer(t)
+	defer srv.Close()
+	ov := srv.Client()
+
+	byName := recordingsByName(t, ov)
+	plan := &openvidu.RetentionPlan{Delete: []*openvidu.RetentionDecision{
+		{Recording: byName["a-2"], Reason: openvidu.RETENTION_MAX_AGE},
+		{Recording: byName["b-1"], Reason: openvidu.RETENTION_MAX_AGE},
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := ov.ExecuteRetentionPlanContext(ctx, plan, time.Hour)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if want := []string{byName["a-2"].Id}; result == nil || !reflect.DeepEqual(result.Deleted, want) {
+		t.Errorf("result = %+v, want %v deleted", result, want)
+	}
+	if recordingsByName(t, ov)["b-1"] == nil {
+		t.Error("recording deleted after cancellation")
+	}
+}
+
+func TestApplyRetentionPolicy(t *testing.T) {
+	srv := newRecordingServer(t)
+	defer srv.Close()
+	ov := srv.Client()
+
+	policy := &openvidu.RetentionPolicy{MaxTotalSize: 20, KeepNamePattern: regexp.MustCompile(`^c-`)}
+	plan, result, err := ov.ApplyRetentionPolicy(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Deleted, plan.RecordingIds()) || len(result.Failed) != 0 {
+		t.Errorf("result = %+v, want %v deleted", result, plan.RecordingIds())
+	}
+	left := recordingsByName(t, ov)
+	if _, ok := left["a-1"]; ok || len(left) != 3 || left["a-2"] != nil {
+		t.Errorf("recordings left = %v, want b-1, b-2 and c-1", left)
+	}
+}
@@ -0,0 +1,204 @@
+package openvidu
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests failing with a network error or a
+// retryable status code are retried.
+//
+// Only idempotent operations (fetching, listing and deleting) are retried,
+// unless RetryGenerateToken or RetryCreateSession are set.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. Following delays
+	// are multiplied by Multiplier up to MaxBackoff. A Retry-After header
+	// sent by the server replaces the delay, still up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// Jitter is the fraction, between 0 and 1, by which each delay is
+	// randomly reduced.
+	Jitter float64
+
+	RetryableStatusCodes []int
+
+	// RetryGenerateToken retries token generation. A retried request may
+	// leave an unused token on the server, which then simply expires.
+	RetryGenerateToken bool
+
+	// RetryCreateSession retries session creation for sessions with a
+	// CustomSessionId. A retry hitting an already created session resolves
+	// to that session, so no duplicate can be created.
+	RetryCreateSession bool
+}
+
+// DefaultRetryPolicy returns a policy making up to 3 attempts with
+// exponential backoff starting at 200ms.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy enables retries of failed requests. Without it every
+// request is attempted exactly once.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(o *clientOptions) {
+		o.retryPolicy = policy
+	}
+}
+
+var idempotentOperations = map[Operation]bool{
+	OP_FETCH_SESSION:      true,
+	OP_FETCH_SESSIONS:     true,
+	OP_CLOSE_SESSION:      true,
+	OP_FORCE_DISCONNECT:   true,
+	OP_FORCE_UNPUBLISH:    true,
+	OP_GET_RECORDING:      true,
+	OP_LIST_RECORDINGS:    true,
+	OP_DELETE_RECORDING:   true,
+	OP_DOWNLOAD_RECORDING: true,
+	OP_GET_CONNECTION:     true,
+	OP_LIST_CONNECTIONS:   true,
+	OP_UPDATE_CONNECTION:  true,
+}
+
+func (rp *RetryPolicy) retries(op Operation) bool {
+	if rp == nil || rp.MaxAttempts <= 1 {
+		return false
+	}
+	switch op {
+	case OP_GENERATE_TOKEN:
+		return rp.RetryGenerateToken
+	case OP_CREATE_SESSION:
+		return rp.RetryCreateSession
+	}
+	return idempotentOperations[op]
+}
+
+func (rp *RetryPolicy) retryableStatus(statusCode int) bool {
+	for _, c := range rp.RetryableStatusCodes {
+		if c == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (rp *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := rp.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	d := float64(rp.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if rp.MaxBackoff > 0 && d > float64(rp.MaxBackoff) {
+		d = float64(rp.MaxBackoff)
+	}
+	if rp.Jitter > 0 {
+		d -= d * math.Min(rp.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// doWithRetry sends req through client, retrying it according to the retry
+// policy of the client when retry is set.
+func (o *OpenVidu) doWithRetry(client *http.Client, req *http.Request, retry bool) (*http.Response, error) {
+	rp := o.retryPolicy
+	if !retry || rp == nil || rp.MaxAttempts <= 1 {
+		return client.Do(req)
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 {
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		response, err := client.Do(r)
+		last := attempt >= rp.MaxAttempts
+		var delay time.Duration
+		if err != nil {
+			if last || ctx.Err() != nil {
+				return nil, err
+			}
+			delay = rp.backoff(attempt)
+		} else {
+			if last || !rp.retryableStatus(response.StatusCode) {
+				return response, nil
+			}
+			delay = rp.backoff(attempt)
+			if d, ok := retryAfter(response); ok {
+				delay = d
+				if rp.MaxBackoff > 0 && delay > rp.MaxBackoff {
+					delay = rp.MaxBackoff
+				}
+			}
+			io.Copy(ioutil.Discard, io.LimitReader(response.Body, maxErrorBodySize))
+			response.Body.Close()
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as an
+// HTTP date.
+func retryAfter(response *http.Response) (time.Duration, bool) {
+	v := response.Header.Get("Retry-After")
+	if len(v) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
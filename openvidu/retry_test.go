@@ -0,0 +1,154 @@
+package openvidu
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyTransport answers the first failures calls to every path with 503
+// Service Unavailable, then 200 OK with body.
+type flakyTransport struct {
+	mu         sync.Mutex
+	failures   int
+	retryAfter string
+	body       string
+	calls      map[string]int
+}
+
+func (t *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.calls == nil {
+		t.calls = make(map[string]int)
+	}
+	key := req.Method + " " + req.URL.Path
+	t.calls[key]++
+
+	response := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(strings.NewReader(t.body)),
+		Request:    req,
+	}
+	if t.calls[key] <= t.failures {
+		response.StatusCode = http.StatusServiceUnavailable
+		response.Body = ioutil.NopCloser(strings.NewReader("unavailable"))
+		if len(t.retryAfter) > 0 {
+			response.Header.Set("Retry-After", t.retryAfter)
+		}
+	}
+	return response, nil
+}
+
+func (t *flakyTransport) count(key string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.calls[key]
+}
+
+func testRetryPolicy() *RetryPolicy {
+	rp := DefaultRetryPolicy()
+	rp.InitialBackoff = time.Millisecond
+	rp.MaxBackoff = time.Millisecond
+	return rp
+}
+
+func TestRetry(t *testing.T) {
+	tr := &flakyTransport{failures: 2, body: `{"numberOfElements":0,"content":[]}`}
+	ov := NewOpenVidu("https://localhost", "secret", WithTransport(tr), WithRetryPolicy(testRetryPolicy()))
+
+	if _, err := ov.Fetch(); err != nil {
+		t.Fatal(err)
+	}
+	if n := tr.count("GET /api/sessions"); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	tr := &flakyTransport{failures: 5}
+	ov := NewOpenVidu("https://localhost", "secret", WithTransport(tr), WithRetryPolicy(testRetryPolicy()))
+
+	_, err := ov.Fetch()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want a 503 APIError", err)
+	}
+	if n := tr.count("GET /api/sessions"); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tr := &flakyTransport{failures: 1, retryAfter: "1", body: `{"numberOfElements":0,"content":[]}`}
+	rp := testRetryPolicy()
+	rp.MaxBackoff = 5 * time.Second
+	ov := NewOpenVidu("https://localhost", "secret", WithTransport(tr), WithRetryPolicy(rp))
+
+	start := time.Now()
+	if _, err := ov.Fetch(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < time.Second {
+		t.Errorf("retried after %v, want at least 1s", d)
+	}
+}
+
+func TestRetryAfterCappedByMaxBackoff(t *testing.T) {
+	tr := &flakyTransport{failures: 1, retryAfter: "86400", body: `{"numberOfElements":0,"content":[]}`}
+	rp := testRetryPolicy()
+	rp.MaxBackoff = 10 * time.Millisecond
+	ov := NewOpenVidu("https://localhost", "secret", WithTransport(tr), WithRetryPolicy(rp))
+
+	start := time.Now()
+	if _, err := ov.Fetch(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("retried after %v, want at most MaxBackoff", d)
+	}
+	if n := tr.count("GET /api/sessions"); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
+
+func TestNoRetryOfNonIdempotentOperations(t *testing.T) {
+	tr := &flakyTransport{failures: 1, body: `{"id":"ses","createdAt":1}`}
+	ov := NewOpenVidu("https://localhost", "secret", WithTransport(tr), WithRetryPolicy(testRetryPolicy()))
+
+	if _, err := ov.CreateSession0(); err == nil {
+		t.Error("CreateSession0 retried")
+	}
+	if n := tr.count("POST /api/sessions"); n != 1 {
+		t.Errorf("CreateSession0 calls = %d, want 1", n)
+	}
+
+	s := &Session{openVidu: ov, SessionId: "ses", activeConnections: make(map[string]*Connection)}
+	if _, err := s.GenerateToken(nil); err == nil {
+		t.Error("GenerateToken retried")
+	}
+	if n := tr.count("POST /api/tokens"); n != 1 {
+		t.Errorf("GenerateToken calls = %d, want 1", n)
+	}
+}
+
+func TestRetryCreateSessionWithCustomId(t *testing.T) {
+	tr := &flakyTransport{failures: 1, body: `{"id":"custom","createdAt":1}`}
+	rp := testRetryPolicy()
+	rp.RetryCreateSession = true
+	ov := NewOpenVidu("https://localhost", "secret", WithTransport(tr), WithRetryPolicy(rp))
+
+	sp := defaultSessionProperties()
+	sp.CustomSessionId = "custom"
+	if _, err := ov.CreateSession1(sp); err != nil {
+		t.Fatal(err)
+	}
+	if n := tr.count("POST /api/sessions"); n != 2 {
+		t.Errorf("calls = %d, want 2", n)
+	}
+}
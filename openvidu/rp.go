@@ -1,13 +1,32 @@
 package openvidu
 
+// Limits of the frame rate and shared memory size of COMPOSED recordings.
+const (
+	MIN_FRAME_RATE = 1
+	MAX_FRAME_RATE = 120
+	MIN_SHM_SIZE   = 134217728
+)
+
 type RecordingProperties struct {
-	Name            string
-	OutputMode      OutputMode
-	RecordingLayout RecordingLayout
-	CustomLayout    string
-	Resolution      string
-	HasVideo        bool
-	HasAudio        bool
+	Name            string          `json:"name"`
+	OutputMode      OutputMode      `json:"outputMode"`
+	RecordingLayout RecordingLayout `json:"recordingLayout,omitempty"`
+	CustomLayout    string          `json:"customLayout,omitempty"`
+	Resolution      string          `json:"resolution,omitempty"`
+	HasVideo        bool            `json:"hasVideo"`
+	HasAudio        bool            `json:"hasAudio"`
+
+	// FrameRate is the frame rate of COMPOSED recordings with video.
+	FrameRate int `json:"frameRate,omitempty"`
+	// ShmSize is the shared memory size, in bytes, of the container
+	// recording COMPOSED recordings with video.
+	ShmSize int64 `json:"shmSize,omitempty"`
+	// IgnoreFailedStreams makes INDIVIDUAL recordings go on when the
+	// recording of one of the streams fails.
+	IgnoreFailedStreams bool `json:"ignoreFailedStreams,omitempty"`
+	// MediaNode is the id of the media node recording the session, in
+	// OpenVidu Pro deployments.
+	MediaNode string `json:"mediaNode,omitempty"`
 }
 
 func (rp *RecordingProperties) Build() *RecordingProperties {
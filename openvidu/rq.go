@@ -0,0 +1,317 @@
+package openvidu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type RecordingSortField string
+
+const (
+	SORT_BY_CREATED_AT RecordingSortField = "createdAt"
+	SORT_BY_SIZE       RecordingSortField = "size"
+	SORT_BY_DURATION   RecordingSortField = "duration"
+)
+
+// RecordingQuery selects the recordings returned by QueryRecordings and
+// IterateRecordings. Zero fields do not filter. As the server always lists
+// every recording, the query is applied by the client while decoding the
+// list, so only the selected recordings are held in memory.
+type RecordingQuery struct {
+	SessionId  string
+	Statuses   []RecordingStatus
+	OutputMode OutputMode
+	NamePrefix string
+
+	// CreatedFrom and CreatedTo bound the creation time of the recordings,
+	// in milliseconds since the epoch. CreatedFrom is inclusive and
+	// CreatedTo exclusive.
+	CreatedFrom int64
+	CreatedTo   int64
+
+	// SortBy sorts the recordings, in ascending order unless Descending is
+	// set. Without it, recordings come in the order of the server and are
+	// streamed without being collected first.
+	SortBy     RecordingSortField
+	Descending bool
+
+	// Offset skips the first recordings selected and Limit, if positive,
+	// caps the number of recordings returned.
+	Offset int
+	Limit  int
+}
+
+// Matches tells whether r passes the filters of the query.
+func (q *RecordingQuery) Matches(r *Recording) bool {
+	if len(q.SessionId) > 0 && r.SessionId != q.SessionId {
+		return false
+	}
+	if len(q.Statuses) > 0 {
+		found := false
+		for _, status := range q.Statuses {
+			if r.Status == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(q.OutputMode) > 0 && r.OutputMode() != q.OutputMode {
+		return false
+	}
+	if len(q.NamePrefix) > 0 && !strings.HasPrefix(r.Name(), q.NamePrefix) {
+		return false
+	}
+	if q.CreatedFrom > 0 && r.CreatedAt < q.CreatedFrom {
+		return false
+	}
+	if q.CreatedTo > 0 && r.CreatedAt >= q.CreatedTo {
+		return false
+	}
+	return true
+}
+
+func (q *RecordingQuery) less(a *Recording, b *Recording) bool {
+	if q.Descending {
+		a, b = b, a
+	}
+	switch q.SortBy {
+	case SORT_BY_SIZE:
+		return a.Size < b.Size
+	case SORT_BY_DURATION:
+		return a.Duration < b.Duration
+	default:
+		return a.CreatedAt < b.CreatedAt
+	}
+}
+
+// QueryRecordings returns the recordings selected by q. A nil q returns
+// every recording.
+func (o *OpenVidu) QueryRecordings(q *RecordingQuery) ([]*Recording, error) {
+	return o.QueryRecordingsContext(context.Background(), q)
+}
+
+func (o *OpenVidu) QueryRecordingsContext(ctx context.Context, q *RecordingQuery) ([]*Recording, error) {
+	it, err := o.IterateRecordingsContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var recordings []*Recording
+	for it.Next() {
+		recordings = append(recordings, it.Recording())
+	}
+	return recordings, it.Err()
+}
+
+// IterateRecordings returns an iterator over the recordings selected by q.
+// A nil q iterates over every recording. The caller must close the
+// iterator.
+func (o *OpenVidu) IterateRecordings(q *RecordingQuery) (*RecordingIterator, error) {
+	return o.IterateRecordingsContext(context.Background(), q)
+}
+
+func (o *OpenVidu) IterateRecordingsContext(ctx context.Context, q *RecordingQuery) (*RecordingIterator, error) {
+	if q == nil {
+		q = &RecordingQuery{}
+	}
+	if q.Offset < 0 || q.Limit < 0 {
+		return nil, fmt.Errorf("openvidu: offset and limit must not be negative, got %d and %d", q.Offset, q.Limit)
+	}
+	switch q.SortBy {
+	case "", SORT_BY_CREATED_AT, SORT_BY_SIZE, SORT_BY_DURATION:
+	default:
+		return nil, fmt.Errorf("openvidu: unknown recording sort field %q", q.SortBy)
+	}
+
+	url := o.hostName + API_RECORDINGS
+	req, err := o.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := o.do(OP_LIST_RECORDINGS, req)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		return nil, newAPIError(OP_LIST_RECORDINGS, response)
+	}
+
+	it := &RecordingIterator{
+		query: q,
+		body:  response.Body,
+		dec:   json.NewDecoder(response.Body),
+	}
+	if err := it.openItems(); err != nil {
+		it.Close()
+		return nil, err
+	}
+
+	if len(q.SortBy) > 0 {
+		var recordings []*Recording
+		for {
+			r, err := it.decode()
+			if err != nil {
+				it.Close()
+				return nil, err
+			}
+			if r == nil {
+				break
+			}
+			recordings = append(recordings, r)
+		}
+		it.Close()
+		sort.SliceStable(recordings, func(i, j int) bool {
+			return q.less(recordings[i], recordings[j])
+		})
+		it.sorted = recordings
+	}
+	return it, nil
+}
+
+// RecordingIterator iterates over the recordings selected by a query,
+// decoding them as they are read from the server:
+//
+//	it, err := ov.IterateRecordings(&openvidu.RecordingQuery{Statuses: []openvidu.RecordingStatus{openvidu.READY}})
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		r := it.Recording()
+//		...
+//	}
+//	return it.Err()
+type RecordingIterator struct {
+	query   *RecordingQuery
+	body    io.ReadCloser
+	dec     *json.Decoder
+	sorted  []*Recording
+	skipped int
+	count   int
+	current *Recording
+	err     error
+}
+
+// Next advances to the next recording, returning false when there are no
+// more recordings or an error occurred.
+func (it *RecordingIterator) Next() bool {
+	it.current = nil
+	if it.err != nil || (it.query.Limit > 0 && it.count >= it.query.Limit) {
+		return false
+	}
+
+	for {
+		var r *Recording
+		if it.sorted != nil || it.body == nil {
+			if len(it.sorted) == 0 {
+				return false
+			}
+			r, it.sorted = it.sorted[0], it.sorted[1:]
+		} else {
+			var err error
+			r, err = it.decode()
+			if err != nil {
+				it.err = err
+				it.Close()
+				return false
+			}
+			if r == nil {
+				it.Close()
+				return false
+			}
+		}
+
+		if it.skipped < it.query.Offset {
+			it.skipped++
+			continue
+		}
+		it.count++
+		it.current = r
+		return true
+	}
+}
+
+// Recording returns the recording Next advanced to.
+func (it *RecordingIterator) Recording() *Recording {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *RecordingIterator) Err() error {
+	return it.err
+}
+
+// Close releases the connection to the server. It can be called at any
+// time, and more than once.
+func (it *RecordingIterator) Close() error {
+	if it.body == nil {
+		return nil
+	}
+	err := it.body.Close()
+	it.body = nil
+	return err
+}
+
+// openItems moves the decoder to the start of the "items" array of the
+// response.
+func (it *RecordingIterator) openItems() error {
+	if err := expectDelim(it.dec, '{'); err != nil {
+		return err
+	}
+	for it.dec.More() {
+		key, err := it.dec.Token()
+		if err != nil {
+			return err
+		}
+		if key == "items" {
+			return expectDelim(it.dec, '[')
+		}
+		var skip json.RawMessage
+		if err := it.dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	// No items at all.
+	it.dec = nil
+	return nil
+}
+
+// decode returns the next recording matching the query, or nil at the end
+// of the list.
+func (it *RecordingIterator) decode() (*Recording, error) {
+	for it.dec != nil && it.dec.More() {
+		var rj *recordingJson
+		if err := it.dec.Decode(&rj); err != nil {
+			return nil, err
+		}
+		if rj == nil {
+			continue
+		}
+		if r := NewRecording(rj); it.query.Matches(r) {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("openvidu: unexpected %v in recording list, want %v", t, delim)
+	}
+	return nil
+}
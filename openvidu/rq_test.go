@@ -0,0 +1,268 @@
+package openvidu_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+// recordingFixture is a recording created by newRecordingServer.
+type recordingFixture struct {
+	sessionId  string
+	name       string
+	outputMode openvidu.OutputMode
+	status     openvidu.RecordingStatus
+	size       int
+}
+
+var recordingFixtures = []recordingFixture{
+	{"ses_a", "a-1", openvidu.COMPOSED, openvidu.READY, 30},
+	{"ses_a", "a-2", openvidu.INDIVIDUAL, openvidu.READY, 10},
+	{"ses_b", "b-1", openvidu.COMPOSED, openvidu.FAILED, 0},
+	{"ses_c", "c-1", openvidu.COMPOSED, openvidu.READY, 20},
+	{"ses_b", "b-2", openvidu.COMPOSED, openvidu.STARTED, 0},
+}
+
+// newRecordingServer returns a server with the recordings of
+// recordingFixtures.
+func newRecordingServer(t *testing.T) *openvidutest.Server {
+	t.Helper()
+	srv := openvidutest.NewServer("secret")
+	ov := srv.Client()
+	for _, f := range recordingFixtures {
+		if ov.GetActiveSession(f.sessionId) == nil {
+			if _, err := ov.CreateSession1(&openvidu.SessionProperties{
+				MediaMode:              openvidu.ROUTED,
+				RecordingMode:          openvidu.MANUAL,
+				DefaultOutputMode:      openvidu.COMPOSED,
+				DefaultRecordingLayout: openvidu.BEST_FIT,
+				CustomSessionId:        f.sessionId,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := srv.JoinSession(f.sessionId, openvidu.PUBLISHER, "", ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		rec, err := ov.StartRecording(f.sessionId, (&openvidu.RecordingProperties{Name: f.name, OutputMode: f.outputMode, HasAudio: true, HasVideo: true}).Build())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.status == openvidu.STARTED {
+			continue
+		}
+		if _, err := ov.StopRecording(rec.Id); err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.SetRecordingMedia(rec.Id, make([]byte, f.size)); err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.SetRecordingStatus(rec.Id, f.status); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srv
+}
+
+func recordingNames(recordings []*openvidu.Recording) []string {
+	names := make([]string, 0, len(recordings))
+	for _, r := range recordings {
+		names = append(names, r.Name())
+	}
+	return names
+}
+
+func TestQueryRecordings(t *testing.T) {
+	srv := newRecordingServer(t)
+	defer srv.Close()
+	ov := srv.Client()
+
+	all, err := ov.ListRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, last := all[0].CreatedAt, all[0].CreatedAt
+	for _, r := range all {
+		if r.CreatedAt < first {
+			first = r.CreatedAt
+		}
+		if r.CreatedAt > last {
+			last = r.CreatedAt
+		}
+	}
+
+	tests := []struct {
+		name   string
+		query  *openvidu.RecordingQuery
+		want   []string
+		sorted bool
+	}{
+		{"nil", nil, []string{"a-1", "a-2", "b-1", "b-2", "c-1"}, false},
+		{"session", &openvidu.RecordingQuery{SessionId: "ses_b"}, []string{"b-1", "b-2"}, false},
+		{"statuses", &openvidu.RecordingQuery{Statuses: []openvidu.RecordingStatus{openvidu.READY, openvidu.FAILED}}, []string{"a-1", "a-2", "b-1", "c-1"}, false},
+		{"output mode", &openvidu.RecordingQuery{OutputMode: openvidu.INDIVIDUAL}, []string{"a-2"}, false},
+		{"name prefix", &openvidu.RecordingQuery{NamePrefix: "a-"}, []string{"a-1", "a-2"}, false},
+		{"created", &openvidu.RecordingQuery{CreatedFrom: first, CreatedTo: last + 1}, []string{"a-1", "a-2", "b-1", "b-2", "c-1"}, false},
+		{"created from", &openvidu.RecordingQuery{CreatedFrom: last + 1}, []string{}, false},
+		{"created to", &openvidu.RecordingQuery{CreatedTo: first}, []string{}, false},
+		{"no match", &openvidu.RecordingQuery{SessionId: "ses_z"}, []string{}, false},
+		{"by size", &openvidu.RecordingQuery{Statuses: []openvidu.RecordingStatus{openvidu.READY}, SortBy: openvidu.SORT_BY_SIZE}, []string{"a-2", "c-1", "a-1"}, true},
+		{"by size descending", &openvidu.RecordingQuery{Statuses: []openvidu.RecordingStatus{openvidu.READY}, SortBy: openvidu.SORT_BY_SIZE, Descending: true}, []string{"a-1", "c-1", "a-2"}, true},
+		{"offset and limit", &openvidu.RecordingQuery{SortBy: openvidu.SORT_BY_SIZE, Descending: true, Offset: 1, Limit: 2}, []string{"c-1", "a-2"}, true},
+		{"offset past the end", &openvidu.RecordingQuery{Offset: 10}, []string{}, false},
+		{"limit above the count", &openvidu.RecordingQuery{SessionId: "ses_a", Limit: 10}, []string{"a-1", "a-2"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordings, err := ov.QueryRecordings(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := recordingNames(recordings)
+			if !tt.sorted {
+				// Without SortBy the order is the server's.
+				sort.Strings(got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recordings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryRecordingsInvalid(t *testing.T) {
+	ov := openvidu.NewOpenVidu("https://localhost", "secret")
+	for _, q := range []*openvidu.RecordingQuery{{Offset: -1}, {Limit: -1}, {SortBy: "name"}} {
+		if _, err := ov.QueryRecordings(q); err == nil {
+			t.Errorf("query %+v accepted", q)
+		}
+	}
+}
+
+func TestQueryRecordingsWithoutItems(t *testing.T) {
+	for _, body := range []string{`{"count":0,"items":[]}`, `{"count":0}`, `{"count":1,"items":[null]}`} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}))
+		ov := openvidu.NewOpenVidu(srv.URL, "secret")
+
+		for _, q := range []*openvidu.RecordingQuery{nil, {SortBy: openvidu.SORT_BY_CREATED_AT}} {
+			recordings, err := ov.QueryRecordings(q)
+			if err != nil || len(recordings) != 0 {
+				t.Errorf("%s: recordings = %v, %v, want none", body, recordings, err)
+			}
+		}
+		srv.Close()
+	}
+}
+
+func TestQueryRecordingsMalformed(t *testing.T) {
+	for _, body := range []string{`[]`, `{"items":{}}`, `{"items":[{"id":1}]}`, `{"items":[`} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}))
+		ov := openvidu.NewOpenVidu(srv.URL, "secret")
+
+		if recordings, err := ov.QueryRecordings(nil); err == nil {
+			t.Errorf("%s: recordings = %v, want an error", body, recordings)
+		}
+		srv.Close()
+	}
+}
+
+// closeTracker counts the response bodies not closed yet.
+type closeTracker struct {
+	mu   sync.Mutex
+	open int
+}
+
+func (ct *closeTracker) RoundTrip(req *http.Request) (*http.Response, error) {
+	response, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	ct.mu.Lock()
+	ct.open++
+	ct.mu.Unlock()
+	response.Body = &trackedBody{ReadCloser: response.Body, tracker: ct}
+	return response, nil
+}
+
+func (ct *closeTracker) count() int {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.open
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	tracker *closeTracker
+	closed  bool
+}
+
+func (b *trackedBody) Close() error {
+	if !b.closed {
+		b.closed = true
+		b.tracker.mu.Lock()
+		b.tracker.open--
+		b.tracker.mu.Unlock()
+	}
+	return b.ReadCloser.Close()
+}
+
+func TestRecordingIteratorClose(t *testing.T) {
+	srv := newRecordingServer(t)
+	defer srv.Close()
+	tracker := &closeTracker{}
+	ov := srv.Client(openvidu.WithTransport(tracker))
+
+	it, err := ov.IterateRecordings(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !it.Next() || it.Recording() == nil {
+		t.Fatalf("no first recording: %v", it.Err())
+	}
+	if n := tracker.count(); n != 1 {
+		t.Fatalf("%d open responses while iterating, want 1", n)
+	}
+	if err := it.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := tracker.count(); n != 0 {
+		t.Errorf("%d open responses after Close, want 0", n)
+	}
+	if it.Next() || it.Recording() != nil || it.Err() != nil {
+		t.Errorf("Next after Close advanced to %+v, err = %v", it.Recording(), it.Err())
+	}
+	if err := it.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+
+	// Iterating to the end and sorting release the response by themselves.
+	it, err = ov.IterateRecordings(&openvidu.RecordingQuery{SessionId: "ses_a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for it.Next() {
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if it, err = ov.IterateRecordings(&openvidu.RecordingQuery{SortBy: openvidu.SORT_BY_SIZE}); err != nil {
+		t.Fatal(err)
+	}
+	if n := tracker.count(); n != 0 {
+		t.Errorf("%d open responses, want 0", n)
+	}
+	it.Close()
+}
@@ -2,19 +2,28 @@ package openvidu
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
+	"net/url"
+	"sync"
 )
 
+// Session is safe for concurrent use. SessionId never changes once the
+// session is created; the rest of its state is kept up to date by the
+// client under an internal lock and is read through accessor methods
+// returning snapshots.
 type Session struct {
+	mu                sync.RWMutex
 	openVidu          *OpenVidu
+	client            SessionClient
 	SessionId         string
-	CreatedAt         int64
-	Properties        *SessionProperties
-	ActiveConnections map[string]*Connection
-	Recording         bool
+	createdAt         int64
+	properties        *SessionProperties
+	activeConnections map[string]*Connection
+	recording         bool
 }
 
 type sessionJson struct {
@@ -45,44 +54,87 @@ type connectionJson struct {
 	Subscribers  []string     `json:"subscribers"`
 }
 
+type connectionRequest struct {
+	Type                    ConnectionType  `json:"type,omitempty"`
+	Data                    string          `json:"data,omitempty"`
+	Role                    OpenViduRole    `json:"role,omitempty"`
+	Record                  *bool           `json:"record,omitempty"`
+	KurentoOptions          *KurentoOptions `json:"kurentoOptions,omitempty"`
+	RtspUri                 string          `json:"rtspUri,omitempty"`
+	Audio                   *bool           `json:"audio,omitempty"`
+	Video                   *bool           `json:"video,omitempty"`
+	AdaptativeBitrate       *bool           `json:"adaptativeBitrate,omitempty"`
+	OnlyPlayWithSubscribers *bool           `json:"onlyPlayWithSubscribers,omitempty"`
+	NetworkCache            int             `json:"networkCache,omitempty"`
+}
+
+type sessionRequest struct {
+	MediaMode              MediaMode       `json:"mediaMode,omitempty"`
+	RecordingMode          RecordingMode   `json:"recordingMode,omitempty"`
+	DefaultOutputMode      OutputMode      `json:"defaultOutputMode,omitempty"`
+	DefaultRecordingLayout RecordingLayout `json:"defaultRecordingLayout,omitempty"`
+	DefaultCustomLayout    string          `json:"defaultCustomLayout,omitempty"`
+	CustomSessionId        string          `json:"customSessionId,omitempty"`
+}
+
 type tokenRequest struct {
 	Session        string          `json:"session"`
 	Role           OpenViduRole    `json:"role"`
 	Data           string          `json:"data"`
-	KurentoOptions *KurentoOptions `json:"kurentoOptions"`
+	KurentoOptions *KurentoOptions `json:"kurentoOptions,omitempty"`
 }
 
 func NewSession0(o *OpenVidu) (*Session, error) {
-	session := &Session{
-		openVidu: o,
-		Properties: &SessionProperties{
-			CustomSessionId:        "",
-			MediaMode:              ROUTED,
-			RecordingMode:          MANUAL,
-			DefaultOutputMode:      COMPOSED,
-			DefaultRecordingLayout: BEST_FIT,
-			DefaultCustomLayout:    "",
-		},
+	return newSession(context.Background(), o, defaultSessionProperties())
+}
+
+func NewSession1(ov *OpenVidu, properties *SessionProperties) (*Session, error) {
+	return newSession(context.Background(), ov, properties)
+}
+
+func newSession(ctx context.Context, ov *OpenVidu, properties *SessionProperties) (*Session, error) {
+	if properties == nil {
+		properties = defaultSessionProperties()
 	}
-	err := session.getSessionIdHttp()
-	if err != nil {
+	if err := properties.Validate(); err != nil {
 		return nil, err
 	}
-	return session, nil
-}
 
-func NewSession1(ov *OpenVidu, properties *SessionProperties) (*Session, error) {
 	session := &Session{
-		openVidu:   ov,
-		Properties: properties,
+		openVidu:          ov,
+		properties:        properties,
+		activeConnections: make(map[string]*Connection),
 	}
-	err := session.getSessionIdHttp()
+	err := session.getSessionIdHttp(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return session, nil
 }
 
+func defaultSessionProperties() *SessionProperties {
+	return &SessionProperties{
+		CustomSessionId:        "",
+		MediaMode:              ROUTED,
+		RecordingMode:          MANUAL,
+		DefaultOutputMode:      COMPOSED,
+		DefaultRecordingLayout: BEST_FIT,
+		DefaultCustomLayout:    "",
+	}
+}
+
+// NewSessionWithClient returns a session whose calls are all made by client
+// instead of an OpenVidu client, e.g. for a fake SessionAPI to return
+// sessions backed by a fake SessionClient. The session does not track any
+// state: its accessors only return the session id.
+func NewSessionWithClient(sessionId string, client SessionClient) *Session {
+	return &Session{
+		SessionId:         sessionId,
+		client:            client,
+		activeConnections: make(map[string]*Connection),
+	}
+}
+
 func NewSession2(ov *OpenVidu, json *serverSession) (*Session, error) {
 	session := &Session{
 		openVidu: ov,
@@ -91,7 +143,16 @@ func NewSession2(ov *OpenVidu, json *serverSession) (*Session, error) {
 	return session, nil
 }
 
-func (s *Session) GenerateToken(to *TokenOptions) (string, error) {
+// GenerateToken creates a token to connect to the session. A nil
+// TokenOptions generates a PUBLISHER token.
+func (s *Session) GenerateToken(to *TokenOptions) (*Token, error) {
+	return s.GenerateTokenContext(context.Background(), to)
+}
+
+func (s *Session) GenerateTokenContext(ctx context.Context, to *TokenOptions) (*Token, error) {
+	if s.client != nil {
+		return s.client.GenerateTokenContext(ctx, to)
+	}
 	if to == nil {
 		to = &TokenOptions{
 			Data: "",
@@ -99,45 +160,33 @@ func (s *Session) GenerateToken(to *TokenOptions) (string, error) {
 		}
 	}
 
+	if err := to.Validate(); err != nil {
+		return nil, err
+	}
+
 	obj := &tokenRequest{
 		Session: s.SessionId,
 		Role:    to.Role,
 		Data:    to.Data,
 	}
-
 	if to.KurentoOptions != nil {
-		if to.KurentoOptions.VideoMaxRecvBandwidth != nil {
-			obj.KurentoOptions.VideoMaxRecvBandwidth = to.KurentoOptions.VideoMaxRecvBandwidth
-		}
-		if to.KurentoOptions.VideoMinRecvBandwidth != nil {
-			obj.KurentoOptions.VideoMinRecvBandwidth = to.KurentoOptions.VideoMinRecvBandwidth
-		}
-		if to.KurentoOptions.VideoMaxSendBandwidth != nil {
-			obj.KurentoOptions.VideoMaxSendBandwidth = to.KurentoOptions.VideoMaxSendBandwidth
-		}
-		if to.KurentoOptions.VideoMinSendBandwidth != nil {
-			obj.KurentoOptions.VideoMinSendBandwidth = to.KurentoOptions.VideoMinSendBandwidth
-		}
-		if len(to.KurentoOptions.AllowedFilters) > 0 {
-			obj.KurentoOptions.AllowedFilters = to.KurentoOptions.AllowedFilters
-		}
+		obj.KurentoOptions = to.KurentoOptions.clone()
 	}
 
 	reqString, err := json.Marshal(obj)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	url := s.openVidu.hostName + API_TOKENS
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqString))
+	req, err := s.openVidu.newRequest(ctx, "POST", url, bytes.NewBuffer(reqString))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Basic "+s.openVidu.basicAuth)
-	response, err := s.openVidu.httpClient.Do(req)
+	response, err := s.openVidu.do(OP_GENERATE_TOKEN, req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer response.Body.Close()
 
@@ -145,40 +194,99 @@ func (s *Session) GenerateToken(to *TokenOptions) (string, error) {
 	if statusCode == http.StatusOK {
 		body, err := ioutil.ReadAll(response.Body)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
-		res := struct {
-			Id string `json:"id"`
-		}{}
-		err = json.Unmarshal(body, &res)
+		var tj tokenJson
+		err = json.Unmarshal(body, &tj)
 		if err != nil {
-			return "", err
+			return nil, err
+		}
+		if len(tj.Session) == 0 {
+			tj.Session = s.SessionId
 		}
 
-		return res.Id, nil
+		return newToken(&tj), nil
 	}
-	return "", newOpenViduError(statusCode)
+	return nil, newAPIError(OP_GENERATE_TOKEN, response)
 }
 
+// GetActiveConnections returns a snapshot of the connections of the session.
+// Modifying the returned connections does not affect the session.
 func (s *Session) GetActiveConnections() []*Connection {
-	v := make([]*Connection, 0, len(s.ActiveConnections))
-	for _, value := range s.ActiveConnections {
-		v = append(v, value)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connectionSnapshots()
+}
+
+// GetActiveConnection returns a snapshot of the connection with the given id,
+// or nil if the session has no such connection.
+func (s *Session) GetActiveConnection(connectionId string) *Connection {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := s.activeConnections[connectionId]
+	if c == nil {
+		return nil
+	}
+	return c.clone()
+}
+
+// GetCreatedAt returns the creation time of the session in milliseconds
+// since the epoch, or 0 if it is not known yet.
+func (s *Session) GetCreatedAt() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.createdAt
+}
+
+// GetProperties returns a copy of the properties of the session.
+func (s *Session) GetProperties() *SessionProperties {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.properties == nil {
+		return nil
+	}
+	p := *s.properties
+	return &p
+}
+
+// IsRecording reports whether the session is being recorded.
+func (s *Session) IsRecording() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.recording
+}
+
+func (s *Session) setRecording(recording bool) {
+	s.openVidu.publishChanges(s.update(func() {
+		s.recording = recording
+	}))
+}
+
+func (s *Session) connectionSnapshots() []*Connection {
+	v := make([]*Connection, 0, len(s.activeConnections))
+	for _, value := range s.activeConnections {
+		v = append(v, value.clone())
 	}
 	return v
 }
 
 func (s *Session) Close() error {
+	return s.CloseContext(context.Background())
+}
+
+func (s *Session) CloseContext(ctx context.Context) error {
+	if s.client != nil {
+		return s.client.CloseContext(ctx)
+	}
 	url := s.openVidu.hostName + API_SESSIONS + "/" + s.SessionId
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := s.openVidu.newRequest(ctx, "DELETE", url, nil)
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Authorization", "Basic "+s.openVidu.basicAuth)
-	response, err := s.openVidu.httpClient.Do(req)
+	response, err := s.openVidu.do(OP_CLOSE_SESSION, req)
 	if err != nil {
 		return err
 	}
@@ -186,30 +294,45 @@ func (s *Session) Close() error {
 
 	statusCode := response.StatusCode
 	if statusCode == http.StatusNoContent {
-		delete(s.openVidu.activeSessions, s.SessionId)
+		s.openVidu.removeActiveSession(s.SessionId)
 	} else {
-		return newOpenViduError(statusCode)
+		return newAPIError(OP_CLOSE_SESSION, response)
 	}
 	return nil
 }
 
 func (s *Session) Fetch() (bool, error) {
-	beforeJson, err := s.ToJson()
-	if err != nil {
-		return false, err
+	return s.FetchContext(context.Background())
+}
+
+func (s *Session) FetchContext(ctx context.Context) (bool, error) {
+	if s.client != nil {
+		return s.client.FetchContext(ctx)
 	}
+	changes, err := s.FetchChangesContext(ctx)
+	return len(changes) > 0, err
+}
+
+// FetchChanges updates the session with its state on the server and returns
+// what changed.
+func (s *Session) FetchChanges() ([]*Change, error) {
+	return s.FetchChangesContext(context.Background())
+}
 
+func (s *Session) FetchChangesContext(ctx context.Context) ([]*Change, error) {
+	if s.client != nil {
+		return s.client.FetchChangesContext(ctx)
+	}
 	url := s.openVidu.hostName + API_SESSIONS + "/" + s.SessionId
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := s.openVidu.newRequest(ctx, "GET", url, nil)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Authorization", "Basic "+s.openVidu.basicAuth)
-	response, err := s.openVidu.httpClient.Do(req)
+	response, err := s.openVidu.do(OP_FETCH_SESSION, req)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	defer response.Body.Close()
 
@@ -217,28 +340,20 @@ func (s *Session) Fetch() (bool, error) {
 	if statusCode == http.StatusOK {
 		body, err := ioutil.ReadAll(response.Body)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
 		var response serverSession
 		err = json.Unmarshal(body, &response)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
-		s.resetSessionWithJson(&response)
-		afterJson, err := s.ToJson()
-		if err != nil {
-			return false, err
-		}
-
-		if strings.Compare(beforeJson, afterJson) != 0 {
-			return true, nil
-		} else {
-			return false, nil
-		}
+		changes := s.applyServerSession(&response)
+		s.openVidu.publishChanges(changes)
+		return changes, nil
 	} else {
-		return false, newOpenViduError(statusCode)
+		return nil, newAPIError(OP_FETCH_SESSION, response)
 	}
 }
 
@@ -246,16 +361,26 @@ func (s *Session) ForceDisconnect(c *Connection) error {
 	return s.ForceDisconnectById(c.ConnectionId)
 }
 
+func (s *Session) ForceDisconnectContext(ctx context.Context, c *Connection) error {
+	return s.ForceDisconnectByIdContext(ctx, c.ConnectionId)
+}
+
 func (s *Session) ForceDisconnectById(connectionId string) error {
+	return s.ForceDisconnectByIdContext(context.Background(), connectionId)
+}
+
+func (s *Session) ForceDisconnectByIdContext(ctx context.Context, connectionId string) error {
+	if s.client != nil {
+		return s.client.ForceDisconnectByIdContext(ctx, connectionId)
+	}
 	url := s.openVidu.hostName + API_SESSIONS + "/" + s.SessionId + "/connection/" + connectionId
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := s.openVidu.newRequest(ctx, "DELETE", url, nil)
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Authorization", "Basic "+s.openVidu.basicAuth)
-	response, err := s.openVidu.httpClient.Do(req)
+	response, err := s.openVidu.do(OP_FORCE_DISCONNECT, req)
 	if err != nil {
 		return err
 	}
@@ -263,25 +388,11 @@ func (s *Session) ForceDisconnectById(connectionId string) error {
 
 	statusCode := response.StatusCode
 	if statusCode == http.StatusNoContent {
-		connectionClosed := s.ActiveConnections[connectionId]
-		delete(s.ActiveConnections, connectionId)
-
-		if connectionClosed != nil {
-			for _, publisher := range connectionClosed.Publishers {
-				streamId := publisher.StreamId
-				for _, connection := range s.ActiveConnections {
-					var newSubscribers []string
-					for _, subscriber := range connection.Subscribers {
-						if strings.Compare(streamId, subscriber) != 0 {
-							newSubscribers = append(newSubscribers, subscriber)
-						}
-					}
-					connection.Subscribers = newSubscribers
-				}
-			}
-		}
+		s.openVidu.publishChanges(s.update(func() {
+			s.removeConnection(connectionId)
+		}))
 	} else {
-		return newOpenViduError(statusCode)
+		return newAPIError(OP_FORCE_DISCONNECT, response)
 	}
 
 	return nil
@@ -291,16 +402,26 @@ func (s *Session) ForceUnpublish(pub *Publisher) error {
 	return s.ForceUnpublishById(pub.StreamId)
 }
 
+func (s *Session) ForceUnpublishContext(ctx context.Context, pub *Publisher) error {
+	return s.ForceUnpublishByIdContext(ctx, pub.StreamId)
+}
+
 func (s *Session) ForceUnpublishById(streamId string) error {
+	return s.ForceUnpublishByIdContext(context.Background(), streamId)
+}
+
+func (s *Session) ForceUnpublishByIdContext(ctx context.Context, streamId string) error {
+	if s.client != nil {
+		return s.client.ForceUnpublishByIdContext(ctx, streamId)
+	}
 	url := s.openVidu.hostName + API_SESSIONS + "/" + s.SessionId + "/stream/" + streamId
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := s.openVidu.newRequest(ctx, "DELETE", url, nil)
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Authorization", "Basic "+s.openVidu.basicAuth)
-	response, err := s.openVidu.httpClient.Do(req)
+	response, err := s.openVidu.do(OP_FORCE_UNPUBLISH, req)
 	if err != nil {
 		return err
 	}
@@ -308,24 +429,276 @@ func (s *Session) ForceUnpublishById(streamId string) error {
 
 	statusCode := response.StatusCode
 	if statusCode == http.StatusNoContent {
-		for _, connection := range s.ActiveConnections {
-			if connection.Publishers[streamId] != nil {
-				delete(connection.Publishers, streamId)
-				continue
-			}
+		s.openVidu.publishChanges(s.update(func() {
+			s.removeStream(streamId)
+		}))
+	} else {
+		return newAPIError(OP_FORCE_UNPUBLISH, response)
+	}
+	return nil
+}
 
-			var newSubscribers []string
-			for _, subscriber := range connection.Subscribers {
-				if strings.Compare(streamId, subscriber) != 0 {
-					newSubscribers = append(newSubscribers, subscriber)
-				}
+// removeConnection must be called with s.mu held.
+func (s *Session) removeConnection(connectionId string) {
+	connectionClosed := s.activeConnections[connectionId]
+	delete(s.activeConnections, connectionId)
+
+	if connectionClosed != nil {
+		for _, publisher := range connectionClosed.Publishers {
+			streamId := publisher.StreamId
+			for _, connection := range s.activeConnections {
+				connection.removeSubscriber(streamId)
 			}
-			connection.Subscribers = newSubscribers
 		}
-	} else {
-		return newOpenViduError(statusCode)
 	}
-	return nil
+}
+
+// removeStream must be called with s.mu held.
+func (s *Session) removeStream(streamId string) {
+	for _, connection := range s.activeConnections {
+		if connection.Publishers[streamId] != nil {
+			delete(connection.Publishers, streamId)
+			continue
+		}
+		connection.removeSubscriber(streamId)
+	}
+}
+
+// CreateConnection creates a connection in the session. The connection is
+// PENDING until a participant connects with its token.
+func (s *Session) CreateConnection(co *ConnectionOptions) (*Connection, error) {
+	return s.CreateConnectionContext(context.Background(), co)
+}
+
+func (s *Session) CreateConnectionContext(ctx context.Context, co *ConnectionOptions) (*Connection, error) {
+	if s.client != nil {
+		return s.client.CreateConnectionContext(ctx, co)
+	}
+	if co == nil {
+		co = &ConnectionOptions{
+			Type: WEBRTC,
+			Role: PUBLISHER,
+		}
+	}
+
+	if co.KurentoOptions != nil {
+		if err := co.KurentoOptions.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	obj := &connectionRequest{
+		Type:           co.Type,
+		Data:           co.Data,
+		Role:           co.Role,
+		Record:         co.Record,
+		KurentoOptions: co.KurentoOptions,
+	}
+	return s.createConnection(ctx, OP_CREATE_CONNECTION, obj)
+}
+
+// PublishIPCamera publishes the RTSP stream of an IP camera in the session.
+// The returned connection is ACTIVE and holds the publisher of the stream.
+// The camera is removed with ForceDisconnectById.
+func (s *Session) PublishIPCamera(rtspUri string, opts *IPCameraOptions) (*Connection, error) {
+	return s.PublishIPCameraContext(context.Background(), rtspUri, opts)
+}
+
+func (s *Session) PublishIPCameraContext(ctx context.Context, rtspUri string, opts *IPCameraOptions) (*Connection, error) {
+	if s.client != nil {
+		return s.client.PublishIPCameraContext(ctx, rtspUri, opts)
+	}
+	if u, err := url.Parse(rtspUri); err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return nil, fmt.Errorf("openvidu: invalid rtspUri %q", rtspUri)
+	}
+	if opts == nil {
+		opts = &IPCameraOptions{}
+	}
+	if opts.NetworkCache < 0 {
+		return nil, fmt.Errorf("openvidu: networkCache must not be negative, got %d", opts.NetworkCache)
+	}
+
+	obj := &connectionRequest{
+		Type:                    IPCAM,
+		Data:                    opts.Data,
+		Record:                  opts.Record,
+		RtspUri:                 rtspUri,
+		Audio:                   opts.Audio,
+		Video:                   opts.Video,
+		AdaptativeBitrate:       opts.AdaptativeBitrate,
+		OnlyPlayWithSubscribers: opts.OnlyPlayWithSubscribers,
+		NetworkCache:            opts.NetworkCache,
+	}
+	return s.createConnection(ctx, OP_PUBLISH_IP_CAMERA, obj)
+}
+
+func (s *Session) createConnection(ctx context.Context, op Operation, obj *connectionRequest) (*Connection, error) {
+	reqString, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	url := s.openVidu.hostName + API_SESSIONS + "/" + s.SessionId + "/connection"
+	req, err := s.openVidu.newRequest(ctx, "POST", url, bytes.NewBuffer(reqString))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	response, err := s.openVidu.do(op, req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	statusCode := response.StatusCode
+	if statusCode == http.StatusOK {
+		return s.readConnection(response)
+	}
+	return nil, newAPIError(op, response)
+}
+
+// GetConnection fetches a connection of the session from the server.
+func (s *Session) GetConnection(connectionId string) (*Connection, error) {
+	return s.GetConnectionContext(context.Background(), connectionId)
+}
+
+func (s *Session) GetConnectionContext(ctx context.Context, connectionId string) (*Connection, error) {
+	if s.client != nil {
+		return s.client.GetConnectionContext(ctx, connectionId)
+	}
+	url := s.openVidu.hostName + API_SESSIONS + "/" + s.SessionId + "/connection/" + connectionId
+	req, err := s.openVidu.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.openVidu.do(OP_GET_CONNECTION, req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	statusCode := response.StatusCode
+	if statusCode == http.StatusOK {
+		return s.readConnection(response)
+	}
+	return nil, newAPIError(OP_GET_CONNECTION, response)
+}
+
+// ListConnections fetches all the connections of the session, pending and
+// active, from the server.
+func (s *Session) ListConnections() ([]*Connection, error) {
+	return s.ListConnectionsContext(context.Background())
+}
+
+func (s *Session) ListConnectionsContext(ctx context.Context) ([]*Connection, error) {
+	if s.client != nil {
+		return s.client.ListConnectionsContext(ctx)
+	}
+	url := s.openVidu.hostName + API_SESSIONS + "/" + s.SessionId + "/connection"
+	req, err := s.openVidu.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.openVidu.do(OP_LIST_CONNECTIONS, req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	statusCode := response.StatusCode
+	if statusCode == http.StatusOK {
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		var ci connectionsInfo
+		err = json.Unmarshal(body, &ci)
+		if err != nil {
+			return nil, err
+		}
+
+		var fetched []*Connection
+		s.openVidu.publishChanges(s.update(func() {
+			s.activeConnections = make(map[string]*Connection, len(ci.Content))
+			for _, con := range ci.Content {
+				c := newConnection(con)
+				s.activeConnections[c.ConnectionId] = c
+				fetched = append(fetched, c.clone())
+			}
+		}))
+		return fetched, nil
+	}
+	return nil, newAPIError(OP_LIST_CONNECTIONS, response)
+}
+
+// UpdateConnection changes the role and the record flag of a pending or
+// active connection. Only the Role and Record options are sent, and unset
+// ones are left unchanged. A nil ConnectionOptions changes nothing.
+func (s *Session) UpdateConnection(connectionId string, co *ConnectionOptions) (*Connection, error) {
+	return s.UpdateConnectionContext(context.Background(), connectionId, co)
+}
+
+func (s *Session) UpdateConnectionContext(ctx context.Context, connectionId string, co *ConnectionOptions) (*Connection, error) {
+	if s.client != nil {
+		return s.client.UpdateConnectionContext(ctx, connectionId, co)
+	}
+	if co == nil {
+		co = &ConnectionOptions{}
+	}
+	obj := &connectionRequest{
+		Role:   co.Role,
+		Record: co.Record,
+	}
+
+	reqString, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	url := s.openVidu.hostName + API_SESSIONS + "/" + s.SessionId + "/connection/" + connectionId
+	req, err := s.openVidu.newRequest(ctx, "PATCH", url, bytes.NewBuffer(reqString))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	response, err := s.openVidu.do(OP_UPDATE_CONNECTION, req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	statusCode := response.StatusCode
+	if statusCode == http.StatusOK {
+		return s.readConnection(response)
+	}
+	return nil, newAPIError(OP_UPDATE_CONNECTION, response)
+}
+
+// readConnection decodes a connection returned by the server and stores it in
+// the active connections of the session.
+func (s *Session) readConnection(response *http.Response) (*Connection, error) {
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var con connectionContent
+	err = json.Unmarshal(body, &con)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newConnection(&con)
+	s.openVidu.publishChanges(s.update(func() {
+		if s.activeConnections == nil {
+			s.activeConnections = make(map[string]*Connection)
+		}
+		s.activeConnections[c.ConnectionId] = c
+	}))
+	return c.clone(), nil
 }
 
 func (s *Session) String() string {
@@ -333,7 +706,13 @@ func (s *Session) String() string {
 }
 
 func (s *Session) ToJson() (string, error) {
-	ac := s.GetActiveConnections()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.toJson()
+}
+
+func (s *Session) toJson() (string, error) {
+	ac := s.connectionSnapshots()
 	var content []*connectionJson
 	for _, con := range ac {
 		cJson := &connectionJson{
@@ -350,14 +729,14 @@ func (s *Session) ToJson() (string, error) {
 
 	sJson := &sessionJson{
 		SessionId:              s.SessionId,
-		CreatedAt:              s.CreatedAt,
-		CustomSessionId:        s.Properties.CustomSessionId,
-		Recording:              s.Recording,
-		MediaMode:              s.Properties.MediaMode,
-		RecordingMode:          s.Properties.RecordingMode,
-		DefaultOutputMode:      s.Properties.DefaultOutputMode,
-		DefaultRecordingLayout: s.Properties.DefaultRecordingLayout,
-		DefaultCustomLayout:    s.Properties.DefaultCustomLayout,
+		CreatedAt:              s.createdAt,
+		CustomSessionId:        s.properties.CustomSessionId,
+		Recording:              s.recording,
+		MediaMode:              s.properties.MediaMode,
+		RecordingMode:          s.properties.RecordingMode,
+		DefaultOutputMode:      s.properties.DefaultOutputMode,
+		DefaultRecordingLayout: s.properties.DefaultRecordingLayout,
+		DefaultCustomLayout:    s.properties.DefaultCustomLayout,
 		Connections: &connections{
 			NumberOfElements: len(ac),
 			Content:          content,
@@ -371,19 +750,19 @@ func (s *Session) ToJson() (string, error) {
 	return string(b), nil
 }
 
-func (s *Session) getSessionIdHttp() error {
+func (s *Session) getSessionIdHttp(ctx context.Context) error {
 	if len(s.SessionId) > 0 {
 		return nil
 	}
 
 	url := s.openVidu.hostName + API_SESSIONS
-	obj := &SessionProperties{
-		MediaMode:              s.Properties.MediaMode,
-		RecordingMode:          s.Properties.RecordingMode,
-		DefaultOutputMode:      s.Properties.DefaultOutputMode,
-		DefaultRecordingLayout: s.Properties.DefaultRecordingLayout,
-		DefaultCustomLayout:    s.Properties.DefaultCustomLayout,
-		CustomSessionId:        s.Properties.CustomSessionId,
+	obj := &sessionRequest{
+		MediaMode:              s.properties.MediaMode,
+		RecordingMode:          s.properties.RecordingMode,
+		DefaultOutputMode:      s.properties.DefaultOutputMode,
+		DefaultRecordingLayout: s.properties.DefaultRecordingLayout,
+		DefaultCustomLayout:    s.properties.DefaultCustomLayout,
+		CustomSessionId:        s.properties.CustomSessionId,
 	}
 
 	reqString, err := json.Marshal(obj)
@@ -391,13 +770,15 @@ func (s *Session) getSessionIdHttp() error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqString))
+	req, err := s.openVidu.newRequest(ctx, "POST", url, bytes.NewBuffer(reqString))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Basic "+s.openVidu.basicAuth)
-	response, err := s.openVidu.httpClient.Do(req)
+	// Only sessions with a custom id can be created idempotently, as a retry
+	// resolves to the session created by a previous attempt.
+	retry := s.openVidu.retryPolicy.retries(OP_CREATE_SESSION) && len(obj.CustomSessionId) > 0
+	response, err := s.openVidu.send(OP_CREATE_SESSION, req, retry)
 	if err != nil {
 		return err
 	}
@@ -419,19 +800,34 @@ func (s *Session) getSessionIdHttp() error {
 		}
 
 		s.SessionId = res.Id
-		s.CreatedAt = res.CreatedAt
+		s.createdAt = res.CreatedAt
 	} else if statusCode == http.StatusConflict {
-		s.SessionId = s.Properties.CustomSessionId
+		// The custom session id is taken: resolve to the existing session.
+		s.SessionId = s.properties.CustomSessionId
 	} else {
-		return newOpenViduError(statusCode)
+		return newAPIError(OP_CREATE_SESSION, response)
 	}
 	return nil
 }
 
+// applyServerSession resets the session with the state fetched from the
+// server and returns what changed. The changes are not published.
+func (s *Session) applyServerSession(sj *serverSession) []*Change {
+	return s.update(func() {
+		s.resetSessionWithJson(sj)
+	})
+}
+
+// resetSessionWithJson must be called with s.mu held, or before the session
+// is shared.
 func (s *Session) resetSessionWithJson(sj *serverSession) {
-	s.SessionId = sj.SessionId
-	s.CreatedAt = sj.CreatedAt
-	s.Recording = sj.Recording
+	// The id never changes once assigned; avoid rewriting it as it is read
+	// without the lock when building request URLs.
+	if s.SessionId != sj.SessionId {
+		s.SessionId = sj.SessionId
+	}
+	s.createdAt = sj.CreatedAt
+	s.recording = sj.Recording
 
 	sp := &SessionProperties{
 		MediaMode:         sj.MediaMode,
@@ -444,50 +840,18 @@ func (s *Session) resetSessionWithJson(sj *serverSession) {
 	if len(sj.DefaultCustomLayout) > 0 {
 		sp.DefaultCustomLayout = sj.DefaultCustomLayout
 	}
-	if s.Properties != nil && len(s.Properties.CustomSessionId) > 0 {
-		sp.CustomSessionId = s.Properties.CustomSessionId
+	if s.properties != nil && len(s.properties.CustomSessionId) > 0 {
+		sp.CustomSessionId = s.properties.CustomSessionId
 	} else if len(sj.CustomSessionId) > 0 {
 		sp.CustomSessionId = sj.CustomSessionId
 	}
-	s.Properties = sp
-
-	connArray := sj.Connections.Content
-	s.ActiveConnections = make(map[string]*Connection, 0)
-	for _, con := range connArray {
-		publishers := con.Publishers
-		pubMap := make(map[string]*Publisher, 0)
-		for _, publisher := range publishers {
-			mediaOptions := publisher.MediaOptions
-			p := &Publisher{
-				CreatedAt:       publisher.CreatedAt,
-				AudioActive:     mediaOptions.AudioActive,
-				FrameRate:       mediaOptions.FrameRate,
-				HasAudio:        mediaOptions.HasAudio,
-				HasVideo:        mediaOptions.HasVideo,
-				StreamId:        publisher.StreamID,
-				TypeOfVideo:     mediaOptions.TypeOfVideo,
-				VideoActive:     mediaOptions.VideoActive,
-				VideoDimensions: mediaOptions.VideoDimensions,
-			}
-			pubMap[p.StreamId] = p
-		}
+	s.properties = sp
 
-		subscribers := make([]string, 0)
-		for _, subscriber := range con.Subscribers {
-			subscribers = append(subscribers, subscriber.StreamID)
-		}
-
-		s.ActiveConnections[con.ConnectionId] = &Connection{
-			CreatedAt:    con.CreatedAt,
-			Subscribers:  subscribers,
-			ServerData:   con.ServerData,
-			ClientData:   con.ClientData,
-			Token:        con.Token,
-			Role:         con.Role,
-			ConnectionId: con.ConnectionId,
-			Publishers:   pubMap,
-			Location:     con.Location,
-			Platform:     con.Platform,
+	s.activeConnections = make(map[string]*Connection, 0)
+	if sj.Connections != nil {
+		for _, con := range sj.Connections.Content {
+			c := newConnection(con)
+			s.activeConnections[c.ConnectionId] = c
 		}
 	}
 }
@@ -0,0 +1,41 @@
+package openvidu_test
+
+import (
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+func TestUpdateConnection(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+
+	session, err := srv.Client().CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := session.CreateConnection(&openvidu.ConnectionOptions{Role: openvidu.SUBSCRIBER})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := session.UpdateConnection(c.ConnectionId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Role != openvidu.SUBSCRIBER || updated.Record != c.Record {
+		t.Errorf("nil options changed the connection: %+v", updated)
+	}
+
+	updated, err = session.UpdateConnection(c.ConnectionId, &openvidu.ConnectionOptions{Role: openvidu.PUBLISHER})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Role != openvidu.PUBLISHER || updated.Record != c.Record {
+		t.Errorf("connection = %+v, want PUBLISHER with record unchanged", updated)
+	}
+	if got := session.GetActiveConnection(c.ConnectionId); got == nil || got.Role != openvidu.PUBLISHER {
+		t.Errorf("cached connection = %+v, want PUBLISHER", got)
+	}
+}
@@ -0,0 +1,78 @@
+package openvidu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+type signalRequest struct {
+	Session string   `json:"session"`
+	To      []string `json:"to,omitempty"`
+	Type    string   `json:"type,omitempty"`
+	Data    string   `json:"data,omitempty"`
+}
+
+// SendSignal sends a signal to the given connections of a session, or to all
+// its participants when no connection id is given.
+func (o *OpenVidu) SendSignal(sessionId string, signalType string, data string, connectionIds ...string) error {
+	return o.SendSignalContext(context.Background(), sessionId, signalType, data, connectionIds...)
+}
+
+func (o *OpenVidu) SendSignalContext(ctx context.Context, sessionId string, signalType string, data string, connectionIds ...string) error {
+	obj := &signalRequest{
+		Session: sessionId,
+		To:      connectionIds,
+		Type:    signalType,
+		Data:    data,
+	}
+
+	reqString, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	url := o.hostName + API_SIGNAL
+	req, err := o.newRequest(ctx, "POST", url, bytes.NewBuffer(reqString))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	response, err := o.do(OP_SIGNAL, req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	statusCode := response.StatusCode
+	if statusCode != http.StatusOK {
+		return newAPIError(OP_SIGNAL, response)
+	}
+	return nil
+}
+
+// Signal sends a signal to the given connections of the session, or to all
+// its participants when no connection is given.
+func (s *Session) Signal(signalType string, data string, to ...*Connection) error {
+	return s.SignalContext(context.Background(), signalType, data, to...)
+}
+
+func (s *Session) SignalContext(ctx context.Context, signalType string, data string, to ...*Connection) error {
+	connectionIds := make([]string, 0, len(to))
+	for _, c := range to {
+		connectionIds = append(connectionIds, c.ConnectionId)
+	}
+	return s.SignalByIdContext(ctx, signalType, data, connectionIds...)
+}
+
+func (s *Session) SignalById(signalType string, data string, connectionIds ...string) error {
+	return s.SignalByIdContext(context.Background(), signalType, data, connectionIds...)
+}
+
+func (s *Session) SignalByIdContext(ctx context.Context, signalType string, data string, connectionIds ...string) error {
+	if s.client != nil {
+		return s.client.SignalByIdContext(ctx, signalType, data, connectionIds...)
+	}
+	return s.openVidu.SendSignalContext(ctx, s.SessionId, signalType, data, connectionIds...)
+}
@@ -1,10 +1,10 @@
 package openvidu
 
 type SessionProperties struct {
-	MediaMode              MediaMode
-	RecordingMode          RecordingMode
-	DefaultOutputMode      OutputMode
-	DefaultRecordingLayout RecordingLayout
-	DefaultCustomLayout    string
-	CustomSessionId        string
+	MediaMode              MediaMode       `json:"mediaMode"`
+	RecordingMode          RecordingMode   `json:"recordingMode"`
+	DefaultOutputMode      OutputMode      `json:"defaultOutputMode"`
+	DefaultRecordingLayout RecordingLayout `json:"defaultRecordingLayout"`
+	DefaultCustomLayout    string          `json:"defaultCustomLayout,omitempty"`
+	CustomSessionId        string          `json:"customSessionId,omitempty"`
 }
@@ -0,0 +1,76 @@
+package openvidu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Storage is where an Archiver stores recordings, e.g. a local directory or
+// an S3-compatible bucket. Keys are slash-separated paths.
+type Storage interface {
+	// Put stores the content read from r under key, replacing any previous
+	// content. It must not leave partial content under key on failure.
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Open returns the content stored under key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// FileStorage is a Storage keeping content in files under a local
+// directory.
+type FileStorage struct {
+	Dir string
+}
+
+func NewFileStorage(dir string) *FileStorage {
+	return &FileStorage{Dir: dir}
+}
+
+func (fs *FileStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	p, err := fs.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(p, func(w io.Writer) error {
+		_, err := io.Copy(w, contextReader{ctx: ctx, r: r})
+		return err
+	})
+}
+
+func (fs *FileStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := fs.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// path maps key to a file under fs.Dir, rejecting keys escaping it.
+func (fs *FileStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "\\") || clean != "/"+key {
+		return "", fmt.Errorf("openvidu: invalid storage key %q", key)
+	}
+	return filepath.Join(fs.Dir, filepath.FromSlash(clean[1:])), nil
+}
+
+// contextReader stops reading once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
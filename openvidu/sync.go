@@ -0,0 +1,139 @@
+package openvidu
+
+import (
+	"context"
+	"time"
+)
+
+// ApplyWebhookEvent incrementally updates the active sessions, connections
+// and publishers known to the client with an event received from the
+// OpenVidu webhook. Events for sessions the client does not know yet create
+// them, so missing details are only filled in by the next Fetch. Events
+// without a session id, or connection events without a connection id, are
+// ignored.
+func (o *OpenVidu) ApplyWebhookEvent(event WebhookEvent) {
+	sessionId := event.EventSessionId()
+	if len(sessionId) == 0 {
+		return
+	}
+
+	switch e := event.(type) {
+	case *SessionDestroyedEvent:
+		o.removeActiveSession(sessionId)
+	case *SessionCreatedEvent:
+		o.getOrCreateActiveSession(sessionId, e.Timestamp)
+	case *ParticipantJoinedEvent:
+		connectionId := eventConnectionId(e.ConnectionId, e.ParticipantId)
+		if len(connectionId) == 0 {
+			return
+		}
+		s := o.getOrCreateActiveSession(sessionId, e.Timestamp)
+		o.publishChanges(s.update(func() {
+			if s.activeConnections[connectionId] == nil {
+				s.activeConnections[connectionId] = &Connection{
+					ConnectionId: connectionId,
+					CreatedAt:    e.Timestamp,
+					Location:     e.Location,
+					Platform:     e.Platform,
+					ServerData:   e.ServerData,
+					ClientData:   e.ClientData,
+					Publishers:   make(map[string]*Publisher),
+					Subscribers:  make([]string, 0),
+				}
+			}
+		}))
+	case *ParticipantLeftEvent:
+		connectionId := eventConnectionId(e.ConnectionId, e.ParticipantId)
+		if len(connectionId) == 0 {
+			return
+		}
+		if s := o.GetActiveSession(sessionId); s != nil {
+			o.publishChanges(s.update(func() {
+				s.removeConnection(connectionId)
+			}))
+		}
+	case *WebrtcConnectionCreatedEvent:
+		connectionId := eventConnectionId(e.ConnectionId, e.ParticipantId)
+		// Servers not sending the stream id cannot be tracked incrementally.
+		if len(connectionId) == 0 || len(e.StreamId) == 0 {
+			return
+		}
+		s := o.getOrCreateActiveSession(sessionId, e.Timestamp)
+		o.publishChanges(s.update(func() {
+			c := s.activeConnections[connectionId]
+			if c != nil {
+				if e.Connection == OUTBOUND {
+					c.Publishers[e.StreamId] = &Publisher{
+						StreamId:        e.StreamId,
+						CreatedAt:       e.Timestamp,
+						HasAudio:        e.AudioEnabled,
+						HasVideo:        e.VideoEnabled,
+						AudioActive:     e.AudioEnabled,
+						VideoActive:     e.VideoEnabled,
+						FrameRate:       e.VideoFramerate,
+						TypeOfVideo:     e.VideoSource,
+						VideoDimensions: e.VideoDimensions,
+					}
+				} else if !contains(c.Subscribers, e.StreamId) {
+					c.Subscribers = append(c.Subscribers, e.StreamId)
+				}
+			}
+		}))
+	case *WebrtcConnectionDestroyedEvent:
+		connectionId := eventConnectionId(e.ConnectionId, e.ParticipantId)
+		if len(connectionId) == 0 || len(e.StreamId) == 0 {
+			return
+		}
+		if s := o.GetActiveSession(sessionId); s != nil {
+			o.publishChanges(s.update(func() {
+				if e.Connection == OUTBOUND {
+					s.removeStream(e.StreamId)
+				} else if c := s.activeConnections[connectionId]; c != nil {
+					c.removeSubscriber(e.StreamId)
+				}
+			}))
+		}
+	case *RecordingStatusChangedEvent:
+		if s := o.GetActiveSession(sessionId); s != nil {
+			s.setRecording(e.Status == STARTING || e.Status == STARTED)
+		}
+	}
+}
+
+// SyncWithWebhooks keeps the active sessions of the client up to date from
+// the events received by h. As webhook deliveries can be lost, the full
+// state is also fetched every reconcileInterval as a safety net, until ctx
+// is done. Events keep being applied after ctx is done. A zero
+// reconcileInterval disables reconciliation.
+func (o *OpenVidu) SyncWithWebhooks(ctx context.Context, h *WebhookHandler, reconcileInterval time.Duration) {
+	h.OnAny(o.ApplyWebhookEvent)
+	if reconcileInterval <= 0 {
+		return
+	}
+
+	opts := DefaultWatcherOptions()
+	opts.Interval = reconcileInterval
+	go o.Watch(ctx, opts)
+}
+
+func (o *OpenVidu) getOrCreateActiveSession(sessionId string, createdAt int64) *Session {
+	o.mu.Lock()
+	s := o.activeSessions[sessionId]
+	if s != nil {
+		o.mu.Unlock()
+		return s
+	}
+
+	s = &Session{
+		openVidu:          o,
+		SessionId:         sessionId,
+		createdAt:         createdAt,
+		properties:        &SessionProperties{},
+		activeConnections: make(map[string]*Connection),
+	}
+	o.activeSessions[sessionId] = s
+	o.mu.Unlock()
+
+	o.publishChanges(diffSessionState(sessionId, nil, s.snapshotState()))
+	return s
+}
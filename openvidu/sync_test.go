@@ -0,0 +1,125 @@
+package openvidu_test
+
+import (
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+func TestApplyWebhookEventToCreatedSession(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := openvidu.EventHeader{SessionId: session.SessionId, Timestamp: 1}
+	ov.ApplyWebhookEvent(&openvidu.ParticipantJoinedEvent{EventHeader: header, ParticipantId: "con_1", ClientData: "client"})
+	ov.ApplyWebhookEvent(&openvidu.WebrtcConnectionCreatedEvent{
+		EventHeader:   header,
+		ParticipantId: "con_1",
+		Connection:    openvidu.OUTBOUND,
+		StreamId:      "str_1",
+		AudioEnabled:  true,
+	})
+
+	c := session.GetActiveConnection("con_1")
+	if c == nil || c.ClientData != "client" {
+		t.Fatalf("connection = %+v, want con_1 with client data", c)
+	}
+	if p := c.Publishers["str_1"]; p == nil || !p.HasAudio {
+		t.Fatalf("publishers = %+v, want str_1 with audio", c.Publishers)
+	}
+
+	ov.ApplyWebhookEvent(&openvidu.ParticipantLeftEvent{EventHeader: header, ParticipantId: "con_1"})
+	if c := session.GetActiveConnection("con_1"); c != nil {
+		t.Fatalf("connection %+v still active after leaving", c)
+	}
+}
+
+func TestApplyWebhookEventCreatesUnknownSession(t *testing.T) {
+	ov := openvidu.NewOpenVidu("https://localhost", "secret")
+
+	header := openvidu.EventHeader{SessionId: "ses_1", Timestamp: 1}
+	ov.ApplyWebhookEvent(&openvidu.ParticipantJoinedEvent{EventHeader: header, ParticipantId: "con_1"})
+
+	s := ov.GetActiveSession("ses_1")
+	if s == nil || s.GetActiveConnection("con_1") == nil {
+		t.Fatal("session and connection not created from the event")
+	}
+
+	ov.ApplyWebhookEvent(&openvidu.SessionDestroyedEvent{EventHeader: header})
+	if ov.GetActiveSession("ses_1") != nil {
+		t.Fatal("session not removed")
+	}
+}
+
+func TestApplyParsedWebhookEvents(t *testing.T) {
+	ov := openvidu.NewOpenVidu("https://localhost", "secret")
+	apply := func(body string) {
+		t.Helper()
+		event, err := openvidu.ParseWebhookEvent([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ov.ApplyWebhookEvent(event)
+	}
+
+	apply(`{"event":"sessionCreated","sessionId":"ses_1","timestamp":1}`)
+	apply(`{"event":"participantJoined","sessionId":"ses_1","timestamp":2,"connectionId":"con_1","clientData":"new"}`)
+	apply(`{"event":"participantJoined","sessionId":"ses_1","timestamp":3,"participantId":"con_2","clientData":"old"}`)
+	apply(`{"event":"webrtcConnectionCreated","sessionId":"ses_1","timestamp":4,"connectionId":"con_1","connection":"OUTBOUND","streamId":"str_1","videoEnabled":true}`)
+	apply(`{"event":"webrtcConnectionCreated","sessionId":"ses_1","timestamp":5,"participantId":"con_2","connection":"INBOUND","receivingFrom":"con_1","streamId":"str_1"}`)
+	apply(`{"event":"recordingStatusChanged","sessionId":"ses_1","timestamp":6,"id":"ses_1","status":"started"}`)
+
+	s := ov.GetActiveSession("ses_1")
+	if s == nil {
+		t.Fatal("session not created")
+	}
+	c1, c2 := s.GetActiveConnection("con_1"), s.GetActiveConnection("con_2")
+	if c1 == nil || c1.ClientData != "new" || c1.Publishers["str_1"] == nil || !c1.Publishers["str_1"].HasVideo {
+		t.Fatalf("con_1 = %+v", c1)
+	}
+	if c2 == nil || c2.ClientData != "old" || len(c2.Subscribers) != 1 || c2.Subscribers[0] != "str_1" {
+		t.Fatalf("con_2 = %+v", c2)
+	}
+	if !s.IsRecording() {
+		t.Error("recording not started")
+	}
+
+	apply(`{"event":"webrtcConnectionDestroyed","sessionId":"ses_1","timestamp":7,"connectionId":"con_2","connection":"INBOUND","streamId":"str_1"}`)
+	if c2 := s.GetActiveConnection("con_2"); c2 == nil || len(c2.Subscribers) != 0 {
+		t.Fatalf("con_2 = %+v, want no subscribers", c2)
+	}
+	apply(`{"event":"participantLeft","sessionId":"ses_1","timestamp":8,"connectionId":"con_1"}`)
+	if c := s.GetActiveConnection("con_1"); c != nil {
+		t.Fatalf("connection %+v still active after leaving", c)
+	}
+	apply(`{"event":"sessionDestroyed","sessionId":"ses_1","timestamp":9}`)
+	if ov.GetActiveSession("ses_1") != nil {
+		t.Fatal("session not removed")
+	}
+}
+
+func TestApplyWebhookEventWithoutConnectionId(t *testing.T) {
+	ov := openvidu.NewOpenVidu("https://localhost", "secret")
+
+	for _, body := range []string{
+		`{"event":"participantJoined","sessionId":"ses_1","timestamp":1}`,
+		`{"event":"webrtcConnectionCreated","sessionId":"ses_1","timestamp":1,"connection":"OUTBOUND","streamId":"str_1"}`,
+	} {
+		event, err := openvidu.ParseWebhookEvent([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ov.ApplyWebhookEvent(event)
+	}
+
+	if s := ov.GetActiveSession("ses_1"); s != nil {
+		t.Fatalf("session %+v created from events without a connection id", s)
+	}
+}
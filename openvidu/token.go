@@ -0,0 +1,119 @@
+package openvidu
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Token is a token generated for a session, to be handed to a participant
+// connecting to it.
+type Token struct {
+	Token          string
+	SessionId      string
+	ConnectionId   string
+	Role           OpenViduRole
+	Data           string
+	KurentoOptions *KurentoOptions
+	CreatedAt      int64
+	URL            *TokenURL
+}
+
+// TokenURL holds the components of a token, which has the form of an URL
+// like wss://host:4443?sessionId=ses_X&token=tok_Y.
+type TokenURL struct {
+	Scheme    string
+	Host      string
+	SessionId string
+	TokenId   string
+
+	// Params holds the other query parameters of the token, except the
+	// secret ones.
+	Params url.Values
+}
+
+type tokenJson struct {
+	Id             string          `json:"id"`
+	Token          string          `json:"token"`
+	Session        string          `json:"session"`
+	ConnectionId   string          `json:"connectionId"`
+	Role           OpenViduRole    `json:"role"`
+	Data           string          `json:"data"`
+	KurentoOptions *KurentoOptions `json:"kurentoOptions"`
+	CreatedAt      int64           `json:"createdAt"`
+}
+
+// secretTokenParams are query parameters of a token never exposed in
+// TokenURL.Params.
+var secretTokenParams = []string{"secret", "turnCredential"}
+
+// ErrInvalidToken is returned by ParseToken for a string that is not an URL
+// with a host, a sessionId and a token parameter.
+var ErrInvalidToken = errors.New("openvidu: invalid token")
+
+func newToken(tj *tokenJson) *Token {
+	t := &Token{
+		Token:          tj.Token,
+		SessionId:      tj.Session,
+		ConnectionId:   tj.ConnectionId,
+		Role:           tj.Role,
+		Data:           tj.Data,
+		KurentoOptions: tj.KurentoOptions,
+		CreatedAt:      tj.CreatedAt,
+	}
+	if len(t.Token) == 0 {
+		t.Token = tj.Id
+	}
+	if tu, err := parseTokenURL(t.Token); err == nil {
+		t.URL = tu
+		if len(t.SessionId) == 0 {
+			t.SessionId = tu.SessionId
+		}
+	}
+	return t
+}
+
+// ParseToken decomposes a token, e.g. one handed back by a frontend. Only
+// the fields carried by the token itself are set.
+func ParseToken(token string) (*Token, error) {
+	tu, err := parseTokenURL(token)
+	if err != nil {
+		return nil, err
+	}
+	return &Token{
+		Token:     token,
+		SessionId: tu.SessionId,
+		Role:      OpenViduRole(tu.Params.Get("role")),
+		URL:       tu,
+	}, nil
+}
+
+func parseTokenURL(token string) (*TokenURL, error) {
+	u, err := url.Parse(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	q := u.Query()
+	tu := &TokenURL{
+		Scheme:    u.Scheme,
+		Host:      u.Host,
+		SessionId: q.Get("sessionId"),
+		TokenId:   q.Get("token"),
+	}
+	if len(tu.Host) == 0 || len(tu.SessionId) == 0 || len(tu.TokenId) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	q.Del("sessionId")
+	q.Del("token")
+	for _, p := range secretTokenParams {
+		q.Del(p)
+	}
+	tu.Params = q
+	return tu, nil
+}
+
+func (t *Token) String() string {
+	return t.Token
+}
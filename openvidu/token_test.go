@@ -0,0 +1,89 @@
+package openvidu_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+)
+
+func TestParseToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		role  openvidu.OpenViduRole
+		url   openvidu.TokenURL
+	}{
+		{
+			"minimal",
+			"wss://localhost:4443?sessionId=ses_1&token=tok_1",
+			"",
+			openvidu.TokenURL{Scheme: "wss", Host: "localhost:4443", SessionId: "ses_1", TokenId: "tok_1", Params: url.Values{}},
+		},
+		{
+			"with path",
+			"wss://example.com/openvidu?sessionId=ses_1&token=tok_1&edition=ce",
+			"",
+			openvidu.TokenURL{Scheme: "wss", Host: "example.com", SessionId: "ses_1", TokenId: "tok_1", Params: url.Values{"edition": {"ce"}}},
+		},
+		{
+			"secret params stripped",
+			"wss://localhost:4443?sessionId=ses_1&token=tok_1&role=PUBLISHER&version=2.15.0&secret=MY_SECRET&turnUsername=user&turnCredential=pass&coturnIp=10.0.0.1",
+			openvidu.PUBLISHER,
+			openvidu.TokenURL{
+				Scheme:    "wss",
+				Host:      "localhost:4443",
+				SessionId: "ses_1",
+				TokenId:   "tok_1",
+				Params: url.Values{
+					"role":         {"PUBLISHER"},
+					"version":      {"2.15.0"},
+					"turnUsername": {"user"},
+					"coturnIp":     {"10.0.0.1"},
+				},
+			},
+		},
+		{
+			"escaped session id",
+			"https://localhost?sessionId=my%20session&token=tok_1&role=MODERATOR",
+			openvidu.MODERATOR,
+			openvidu.TokenURL{Scheme: "https", Host: "localhost", SessionId: "my session", TokenId: "tok_1", Params: url.Values{"role": {"MODERATOR"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := openvidu.ParseToken(tt.token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.Token != tt.token || token.SessionId != tt.url.SessionId || token.Role != tt.role {
+				t.Errorf("token = %+v", token)
+			}
+			if !reflect.DeepEqual(*token.URL, tt.url) {
+				t.Errorf("URL = %+v, want %+v", *token.URL, tt.url)
+			}
+			if token.String() != tt.token {
+				t.Errorf("String() = %q, want %q", token.String(), tt.token)
+			}
+		})
+	}
+}
+
+func TestParseInvalidToken(t *testing.T) {
+	for _, token := range []string{
+		"",
+		"tok_1",
+		"wss://localhost:4443",
+		"wss://localhost:4443?sessionId=ses_1",
+		"wss://localhost:4443?token=tok_1",
+		"wss://?sessionId=ses_1&token=tok_1",
+		"wss://local host?sessionId=ses_1&token=tok_1",
+		"wss://localhost:4443?sessionId=&token=tok_1",
+	} {
+		if parsed, err := openvidu.ParseToken(token); !errors.Is(err, openvidu.ErrInvalidToken) {
+			t.Errorf("ParseToken(%q) = %+v, %v, want ErrInvalidToken", token, parsed, err)
+		}
+	}
+}
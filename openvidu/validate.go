@@ -0,0 +1,197 @@
+package openvidu
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidProperties is matched by every ValidationError.
+var ErrInvalidProperties = errors.New("openvidu: invalid properties")
+
+// FieldError describes why the value of a field is invalid. Field is the
+// JSON name of the field, prefixed with the names of its parents.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (fe *FieldError) Error() string {
+	return "openvidu: " + fe.Field + " " + fe.Reason
+}
+
+// ValidationError lists every invalid field of the properties, options or
+// token options given to the client, which rejects them before contacting
+// the server.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (ve *ValidationError) Error() string {
+	reasons := make([]string, 0, len(ve.Fields))
+	for _, fe := range ve.Fields {
+		reasons = append(reasons, fe.Field+" "+fe.Reason)
+	}
+	return "openvidu: " + strings.Join(reasons, "; ")
+}
+
+func (ve *ValidationError) Unwrap() error {
+	return ErrInvalidProperties
+}
+
+// fieldErrors accumulates the invalid fields found by a Validate method.
+type fieldErrors []*FieldError
+
+func (fe *fieldErrors) add(field string, format string, args ...interface{}) {
+	*fe = append(*fe, &FieldError{Field: field, Reason: fmt.Sprintf(format, args...)})
+}
+
+// err returns a *ValidationError with the invalid fields, or nil if there
+// are none.
+func (fe fieldErrors) err() error {
+	if len(fe) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fe}
+}
+
+// Validate reports every invalid property: unknown modes or layouts, a
+// recording with neither audio nor video, a malformed resolution, a CUSTOM
+// layout without custom layout or a custom layout without CUSTOM layout,
+// a frame rate or shared memory size out of range, and options set for
+// recordings they do not apply to.
+func (rp *RecordingProperties) Validate() error {
+	var fe fieldErrors
+
+	switch rp.OutputMode {
+	case "", COMPOSED, INDIVIDUAL:
+	default:
+		fe.add("outputMode", "has unknown value %q", rp.OutputMode)
+	}
+	if !rp.HasAudio && !rp.HasVideo {
+		fe.add("hasAudio", "and hasVideo must not both be false")
+	}
+
+	if rp.FrameRate != 0 && (rp.FrameRate < MIN_FRAME_RATE || rp.FrameRate > MAX_FRAME_RATE) {
+		fe.add("frameRate", "must be between %d and %d, got %d", MIN_FRAME_RATE, MAX_FRAME_RATE, rp.FrameRate)
+	}
+	if rp.ShmSize != 0 && rp.ShmSize < MIN_SHM_SIZE {
+		fe.add("shmSize", "must be at least %d bytes, got %d", MIN_SHM_SIZE, rp.ShmSize)
+	}
+	// Options not applying to the recording are not sent, so they are
+	// rejected rather than silently dropped.
+	if rp.OutputMode != COMPOSED || !rp.HasVideo {
+		reason := "only applies to COMPOSED recordings with video"
+		if rp.FrameRate != 0 {
+			fe.add("frameRate", reason)
+		}
+		if rp.ShmSize != 0 {
+			fe.add("shmSize", reason)
+		}
+	}
+	if rp.IgnoreFailedStreams && rp.OutputMode != INDIVIDUAL {
+		fe.add("ignoreFailedStreams", "only applies to INDIVIDUAL recordings")
+	}
+
+	if rp.OutputMode == INDIVIDUAL {
+		reason := "only applies to COMPOSED recordings"
+		if len(rp.Resolution) > 0 {
+			fe.add("resolution", reason)
+		}
+		if len(rp.RecordingLayout) > 0 {
+			fe.add("recordingLayout", reason)
+		}
+		if len(rp.CustomLayout) > 0 {
+			fe.add("customLayout", reason)
+		}
+		return fe.err()
+	}
+	// Layout options are not sent for audio-only recordings.
+	if !rp.HasVideo {
+		return fe.err()
+	}
+
+	if len(rp.Resolution) > 0 && !validResolution(rp.Resolution) {
+		fe.add("resolution", "must be WIDTHxHEIGHT with both between %d and %d, got %q",
+			MIN_RESOLUTION_SIDE, MAX_RESOLUTION_SIDE, rp.Resolution)
+	}
+	validateLayout(&fe, "recordingLayout", rp.RecordingLayout, "customLayout", rp.CustomLayout)
+	return fe.err()
+}
+
+func validResolution(resolution string) bool {
+	r, err := ParseResolution(resolution)
+	return err == nil && r.Recordable()
+}
+
+func validateLayout(fe *fieldErrors, layoutField string, layout RecordingLayout, customField string, custom string) {
+	switch layout {
+	case "", BEST_FIT, PICTURE_IN_PICTURE, VERTICAL_PRESENTATION, HORIZONTAL_PRESENTATION:
+		if len(custom) > 0 {
+			fe.add(customField, "requires %s %s", layoutField, CUSTOM)
+		}
+	case CUSTOM:
+		if len(custom) == 0 {
+			fe.add(customField, "is required by %s %s", layoutField, CUSTOM)
+		}
+	default:
+		fe.add(layoutField, "has unknown value %q", layout)
+	}
+}
+
+var customSessionIdPattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// Validate reports every invalid property: unknown modes or layouts, a
+// custom session id with characters other than letters, digits, "_" and
+// "-", and ALWAYS recording in a RELAYED session, which cannot be recorded.
+func (sp *SessionProperties) Validate() error {
+	var fe fieldErrors
+
+	switch sp.MediaMode {
+	case "", ROUTED, RELAYED:
+	default:
+		fe.add("mediaMode", "has unknown value %q", sp.MediaMode)
+	}
+	switch sp.RecordingMode {
+	case "", MANUAL:
+	case ALWAYS:
+		if sp.MediaMode == RELAYED {
+			fe.add("recordingMode", "%s requires mediaMode %s", ALWAYS, ROUTED)
+		}
+	default:
+		fe.add("recordingMode", "has unknown value %q", sp.RecordingMode)
+	}
+	switch sp.DefaultOutputMode {
+	case "", COMPOSED, INDIVIDUAL:
+	default:
+		fe.add("defaultOutputMode", "has unknown value %q", sp.DefaultOutputMode)
+	}
+	validateLayout(&fe, "defaultRecordingLayout", sp.DefaultRecordingLayout, "defaultCustomLayout", sp.DefaultCustomLayout)
+	if !customSessionIdPattern.MatchString(sp.CustomSessionId) {
+		fe.add("customSessionId", "must only contain letters, digits, _ and -, got %q", sp.CustomSessionId)
+	}
+	return fe.err()
+}
+
+// Validate reports every invalid option: an unknown role and the invalid
+// KurentoOptions.
+func (to *TokenOptions) Validate() error {
+	var fe fieldErrors
+
+	if len(to.Role) > 0 && !validRole(to.Role) {
+		fe.add("role", "has unknown value %q", to.Role)
+	}
+	if to.KurentoOptions != nil {
+		to.KurentoOptions.validate(&fe)
+	}
+	return fe.err()
+}
+
+func validRole(role OpenViduRole) bool {
+	switch role {
+	case SUBSCRIBER, PUBLISHER, MODERATOR:
+		return true
+	}
+	return false
+}
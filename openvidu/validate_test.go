@@ -0,0 +1,166 @@
+package openvidu_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+func TestRecordingPropertiesValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		rp     openvidu.RecordingProperties
+		fields []string
+	}{
+		{"composed", openvidu.RecordingProperties{OutputMode: openvidu.COMPOSED, HasAudio: true, HasVideo: true, FrameRate: 30, ShmSize: openvidu.MIN_SHM_SIZE}, nil},
+		{"individual", openvidu.RecordingProperties{OutputMode: openvidu.INDIVIDUAL, HasAudio: true, HasVideo: true, IgnoreFailedStreams: true}, nil},
+		{"no media", openvidu.RecordingProperties{OutputMode: openvidu.COMPOSED}, []string{"hasAudio"}},
+		{"frame rate out of range", openvidu.RecordingProperties{OutputMode: openvidu.COMPOSED, HasVideo: true, FrameRate: 500}, []string{"frameRate"}},
+		{"small shm size", openvidu.RecordingProperties{OutputMode: openvidu.COMPOSED, HasVideo: true, ShmSize: 1024}, []string{"shmSize"}},
+		{"audio-only composed", openvidu.RecordingProperties{OutputMode: openvidu.COMPOSED, HasAudio: true, FrameRate: 30, ShmSize: openvidu.MIN_SHM_SIZE}, []string{"frameRate", "shmSize"}},
+		{"individual with composed options", openvidu.RecordingProperties{OutputMode: openvidu.INDIVIDUAL, HasVideo: true, FrameRate: 30, Resolution: "640x480"}, []string{"frameRate", "resolution"}},
+		{"composed with individual options", openvidu.RecordingProperties{OutputMode: openvidu.COMPOSED, HasVideo: true, IgnoreFailedStreams: true}, []string{"ignoreFailedStreams"}},
+		{"unrecordable resolution", openvidu.RecordingProperties{OutputMode: openvidu.COMPOSED, HasVideo: true, Resolution: "3840x2160"}, []string{"resolution"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkInvalidFields(t, tt.rp.Validate(), tt.fields)
+		})
+	}
+}
+
+// checkInvalidFields checks that err is nil when no fields are given, or
+// else a ValidationError for exactly these fields.
+func checkInvalidFields(t *testing.T, err error, fields []string) {
+	t.Helper()
+	if len(fields) == 0 {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	var ve *openvidu.ValidationError
+	if !errors.As(err, &ve) || !errors.Is(err, openvidu.ErrInvalidProperties) {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+	var got []string
+	for _, fe := range ve.Fields {
+		got = append(got, fe.Field)
+	}
+	if !reflect.DeepEqual(got, fields) {
+		t.Fatalf("fields = %v, want %v", got, fields)
+	}
+}
+
+func TestRecordingOptionsServerVersion(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+
+	session, err := srv.Client().CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	rp := &openvidu.RecordingProperties{OutputMode: openvidu.INDIVIDUAL, HasAudio: true, HasVideo: true, IgnoreFailedStreams: true}
+	old := srv.Client(openvidu.WithServerVersion("v2.16.1-beta"))
+	if _, err := old.StartRecording(session.SessionId, rp); !errors.Is(err, openvidu.ErrUnsupportedOption) {
+		t.Fatalf("err = %v, want ErrUnsupportedOption", err)
+	}
+
+	rec, err := srv.Client(openvidu.WithServerVersion("2.17.0")).StartRecording(session.SessionId, rp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.RecordingProperties.IgnoreFailedStreams {
+		t.Error("ignoreFailedStreams not sent")
+	}
+}
+
+func TestSessionPropertiesValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		sp     openvidu.SessionProperties
+		fields []string
+	}{
+		{"zero", openvidu.SessionProperties{}, nil},
+		{"full", openvidu.SessionProperties{MediaMode: openvidu.ROUTED, RecordingMode: openvidu.ALWAYS, DefaultOutputMode: openvidu.INDIVIDUAL, DefaultRecordingLayout: openvidu.BEST_FIT, CustomSessionId: "Session_1-a"}, nil},
+		{"custom layout", openvidu.SessionProperties{DefaultRecordingLayout: openvidu.CUSTOM, DefaultCustomLayout: "layouts/grid"}, nil},
+		{"unknown modes", openvidu.SessionProperties{MediaMode: "MIXED", RecordingMode: "SOMETIMES", DefaultOutputMode: "BOTH"}, []string{"mediaMode", "recordingMode", "defaultOutputMode"}},
+		{"always relayed", openvidu.SessionProperties{MediaMode: openvidu.RELAYED, RecordingMode: openvidu.ALWAYS}, []string{"recordingMode"}},
+		{"manual relayed", openvidu.SessionProperties{MediaMode: openvidu.RELAYED, RecordingMode: openvidu.MANUAL}, nil},
+		{"unknown layout", openvidu.SessionProperties{DefaultRecordingLayout: "GRID"}, []string{"defaultRecordingLayout"}},
+		{"custom without layout", openvidu.SessionProperties{DefaultRecordingLayout: openvidu.CUSTOM}, []string{"defaultCustomLayout"}},
+		{"layout without custom", openvidu.SessionProperties{DefaultRecordingLayout: openvidu.BEST_FIT, DefaultCustomLayout: "layouts/grid"}, []string{"defaultCustomLayout"}},
+		{"bad custom session id", openvidu.SessionProperties{CustomSessionId: "my session/1"}, []string{"customSessionId"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkInvalidFields(t, tt.sp.Validate(), tt.fields)
+		})
+	}
+}
+
+func TestTokenOptionsValidate(t *testing.T) {
+	negative, low, high := int32(-1), int32(100), int32(1000)
+	tests := []struct {
+		name   string
+		to     openvidu.TokenOptions
+		fields []string
+	}{
+		{"zero", openvidu.TokenOptions{}, nil},
+		{"full", openvidu.TokenOptions{Role: openvidu.MODERATOR, Data: "data", KurentoOptions: &openvidu.KurentoOptions{
+			VideoMinRecvBandwidth: &low,
+			VideoMaxRecvBandwidth: &high,
+			AllowedFilters:        []string{"GStreamerFilter", "ZBarFilter"},
+		}}, nil},
+		{"unknown role", openvidu.TokenOptions{Role: "ADMIN"}, []string{"role"}},
+		{"negative bandwidth", openvidu.TokenOptions{KurentoOptions: &openvidu.KurentoOptions{VideoMaxSendBandwidth: &negative}}, []string{"kurentoOptions.videoMaxSendBandwidth"}},
+		{"min above max", openvidu.TokenOptions{KurentoOptions: &openvidu.KurentoOptions{VideoMinSendBandwidth: &high, VideoMaxSendBandwidth: &low}}, []string{"kurentoOptions.videoMinSendBandwidth"}},
+		{"bad filters", openvidu.TokenOptions{KurentoOptions: &openvidu.KurentoOptions{AllowedFilters: []string{"1Filter", "ZBarFilter", "ZBarFilter"}}}, []string{"kurentoOptions.allowedFilters", "kurentoOptions.allowedFilters"}},
+		{"every error", openvidu.TokenOptions{Role: "ADMIN", KurentoOptions: &openvidu.KurentoOptions{VideoMinRecvBandwidth: &negative}}, []string{"role", "kurentoOptions.videoMinRecvBandwidth"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkInvalidFields(t, tt.to.Validate(), tt.fields)
+		})
+	}
+}
+
+func TestInvalidInputNotSent(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+
+	var calls []openvidu.Operation
+	ov := srv.Client(openvidu.WithMiddleware(func(next openvidu.Handler) openvidu.Handler {
+		return func(call *openvidu.Call) (*http.Response, error) {
+			calls = append(calls, call.Operation)
+			return next(call)
+		}
+	}))
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls = nil
+
+	if _, err := ov.CreateSession1(&openvidu.SessionProperties{MediaMode: openvidu.RELAYED, RecordingMode: openvidu.ALWAYS}); !errors.Is(err, openvidu.ErrInvalidProperties) {
+		t.Errorf("CreateSession1: err = %v, want ErrInvalidProperties", err)
+	}
+	if _, err := session.GenerateToken(&openvidu.TokenOptions{Role: "ADMIN"}); !errors.Is(err, openvidu.ErrInvalidProperties) {
+		t.Errorf("GenerateToken: err = %v, want ErrInvalidProperties", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("requests sent: %v", calls)
+	}
+	if ids := srv.SessionIds(); len(ids) != 1 {
+		t.Errorf("sessions = %v, want only %s", ids, session.SessionId)
+	}
+}
@@ -0,0 +1,100 @@
+package openvidu
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedOption is returned when an option is not supported by the
+// server version declared with WithServerVersion.
+var ErrUnsupportedOption = errors.New("openvidu: option not supported by the server version")
+
+// WithServerVersion declares the version of the OpenVidu server, like
+// "2.16.0", making the client reject the options the server does not
+// support instead of letting the server ignore them. Without it, every
+// option is sent.
+func WithServerVersion(version string) Option {
+	return func(o *clientOptions) {
+		o.serverVersion = version
+	}
+}
+
+// recordingOptions lists the recording options added after the first
+// versions of OpenVidu, with the version adding them.
+var recordingOptions = []struct {
+	name    string
+	version string
+	isSet   func(rp *RecordingProperties) bool
+}{
+	{"frameRate", "2.16.0", func(rp *RecordingProperties) bool { return rp.FrameRate != 0 }},
+	{"shmSize", "2.16.0", func(rp *RecordingProperties) bool { return rp.ShmSize != 0 }},
+	{"mediaNode", "2.16.0", func(rp *RecordingProperties) bool { return len(rp.MediaNode) > 0 }},
+	{"ignoreFailedStreams", "2.17.0", func(rp *RecordingProperties) bool { return rp.IgnoreFailedStreams }},
+}
+
+func (o *OpenVidu) checkRecordingOptions(rp *RecordingProperties) error {
+	if len(o.serverVersion) == 0 {
+		return nil
+	}
+	server, err := parseVersion(o.serverVersion)
+	if err != nil {
+		return err
+	}
+
+	for _, opt := range recordingOptions {
+		if !opt.isSet(rp) {
+			continue
+		}
+		required, err := parseVersion(opt.version)
+		if err != nil {
+			return err
+		}
+		if compareVersions(server, required) < 0 {
+			return fmt.Errorf("%w: %s requires OpenVidu %s, server is %s", ErrUnsupportedOption, opt.name, opt.version, o.serverVersion)
+		}
+	}
+	return nil
+}
+
+// parseVersion parses a "MAJOR.MINOR.PATCH" version, with an optional "v"
+// prefix and pre-release suffix, which is ignored.
+func parseVersion(version string) ([]int, error) {
+	v := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	parts := strings.Split(v, ".")
+	numbers := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("openvidu: invalid server version %q", version)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// compareVersions returns -1, 0 or 1 as a is below, equal to or above b.
+// Missing components count as 0.
+func compareVersions(a []int, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package openvidu
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WaitOptions configures OpenVidu.WaitForRecordingWithOptions.
+type WaitOptions struct {
+	// Interval is the delay before the first poll of the recording, which
+	// doubles after every poll up to MaxInterval. It defaults to 1 second,
+	// and a MaxInterval not above it disables the backoff.
+	Interval    time.Duration
+	MaxInterval time.Duration
+
+	// Webhook, if set, makes the recordingStatusChanged events it receives
+	// for the recording trigger an immediate poll, so the wait ends as soon
+	// as OpenVidu reports the status. Polling goes on in case an event is
+	// lost.
+	Webhook *WebhookHandler
+
+	// OnStatusChange, if set, is called with every status observed for the
+	// recording, including the first one, for which previous is empty.
+	OnStatusChange func(previous RecordingStatus, r *Recording)
+}
+
+// DefaultWaitOptions polls after 1 second, backing off up to 30 seconds.
+func DefaultWaitOptions() *WaitOptions {
+	return &WaitOptions{
+		Interval:    time.Second,
+		MaxInterval: 30 * time.Second,
+	}
+}
+
+// WaitForRecording polls a recording until it reaches one of the target
+// statuses and returns it. Without target statuses it waits for the
+// recording to be READY or FAILED. A recording FAILED while waiting for
+// other statuses is returned along with ErrRecordingFailed.
+func (o *OpenVidu) WaitForRecording(recordingId string, targetStatuses ...RecordingStatus) (*Recording, error) {
+	return o.WaitForRecordingContext(context.Background(), recordingId, targetStatuses...)
+}
+
+func (o *OpenVidu) WaitForRecordingContext(ctx context.Context, recordingId string, targetStatuses ...RecordingStatus) (*Recording, error) {
+	return o.WaitForRecordingWithOptions(ctx, recordingId, nil, targetStatuses...)
+}
+
+// WaitForRecordingWithOptions is like WaitForRecording, configured by opts.
+// A nil opts uses DefaultWaitOptions.
+func (o *OpenVidu) WaitForRecordingWithOptions(ctx context.Context, recordingId string, opts *WaitOptions, targetStatuses ...RecordingStatus) (*Recording, error) {
+	if opts == nil {
+		opts = DefaultWaitOptions()
+	}
+	if len(targetStatuses) == 0 {
+		targetStatuses = []RecordingStatus{READY, FAILED}
+	}
+
+	var wakeUp chan struct{}
+	if opts.Webhook != nil {
+		wakeUp = make(chan struct{}, 1)
+		cancel := opts.Webhook.subscribe(func(event WebhookEvent) {
+			if e, ok := event.(*RecordingStatusChangedEvent); ok && e.Id == recordingId {
+				select {
+				case wakeUp <- struct{}{}:
+				default:
+				}
+			}
+		})
+		defer cancel()
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWaitOptions().Interval
+	}
+	var status RecordingStatus
+	for {
+		r, err := o.GetRecordingContext(ctx, recordingId)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.Status != status {
+			if opts.OnStatusChange != nil {
+				opts.OnStatusChange(status, r)
+			}
+			status = r.Status
+		}
+		for _, target := range targetStatuses {
+			if status == target {
+				return r, nil
+			}
+		}
+		if status == FAILED {
+			return r, fmt.Errorf("%w: recording %s", ErrRecordingFailed, recordingId)
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-wakeUp:
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		if interval < opts.MaxInterval {
+			interval *= 2
+			if interval > opts.MaxInterval {
+				interval = opts.MaxInterval
+			}
+		}
+	}
+}
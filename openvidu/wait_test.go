@@ -0,0 +1,188 @@
+package openvidu_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+	"github.com/anidotnet/openvidu-go-client/openvidu/openvidutest"
+)
+
+// stoppedRecording records a new session and stops the recording.
+func stoppedRecording(t *testing.T, srv *openvidutest.Server, ov *openvidu.OpenVidu) *openvidu.Recording {
+	t.Helper()
+	session, err := ov.CreateSession0()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.JoinSession(session.SessionId, openvidu.PUBLISHER, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := ov.StartRecordingById(session.SessionId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec, err = ov.StopRecording(rec.Id); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+type statusChange struct {
+	previous openvidu.RecordingStatus
+	status   openvidu.RecordingStatus
+}
+
+type waitResult struct {
+	r   *openvidu.Recording
+	err error
+}
+
+// startWait waits for the recording in the background, sending every
+// status change observed to the returned channel.
+func startWait(ctx context.Context, ov *openvidu.OpenVidu, recordingId string, opts *openvidu.WaitOptions, targets ...openvidu.RecordingStatus) (<-chan statusChange, <-chan waitResult) {
+	changes := make(chan statusChange, 10)
+	done := make(chan waitResult, 1)
+	opts.OnStatusChange = func(previous openvidu.RecordingStatus, r *openvidu.Recording) {
+		changes <- statusChange{previous, r.Status}
+	}
+	go func() {
+		r, err := ov.WaitForRecordingWithOptions(ctx, recordingId, opts, targets...)
+		done <- waitResult{r, err}
+	}()
+	return changes, done
+}
+
+func nextChange(t *testing.T, changes <-chan statusChange) statusChange {
+	t.Helper()
+	select {
+	case c := <-changes:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status change")
+	}
+	return statusChange{}
+}
+
+func waitDone(t *testing.T, done <-chan waitResult) waitResult {
+	t.Helper()
+	select {
+	case res := <-done:
+		return res
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForRecordingWithOptions did not return")
+	}
+	return waitResult{}
+}
+
+func TestWaitForRecordingReady(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+	rec := stoppedRecording(t, srv, ov)
+
+	changes, done := startWait(context.Background(), ov, rec.Id, &openvidu.WaitOptions{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond})
+	if c := nextChange(t, changes); c != (statusChange{"", openvidu.STOPPED}) {
+		t.Errorf("first change = %+v, want STOPPED", c)
+	}
+	if err := srv.SetRecordingStatus(rec.Id, openvidu.READY); err != nil {
+		t.Fatal(err)
+	}
+	if c := nextChange(t, changes); c != (statusChange{openvidu.STOPPED, openvidu.READY}) {
+		t.Errorf("second change = %+v, want STOPPED to READY", c)
+	}
+
+	res := waitDone(t, done)
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+	if res.r.Status != openvidu.READY || len(res.r.Url) == 0 {
+		t.Errorf("recording = %+v", res.r)
+	}
+	if len(changes) != 0 {
+		t.Errorf("%d more status changes", len(changes))
+	}
+}
+
+func TestWaitForRecordingFailed(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+	rec := stoppedRecording(t, srv, ov)
+	if err := srv.SetRecordingStatus(rec.Id, openvidu.FAILED); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &openvidu.WaitOptions{Interval: time.Millisecond}
+	r, err := ov.WaitForRecordingWithOptions(context.Background(), rec.Id, opts, openvidu.READY)
+	if !errors.Is(err, openvidu.ErrRecordingFailed) {
+		t.Errorf("err = %v, want ErrRecordingFailed", err)
+	}
+	if r == nil || r.Status != openvidu.FAILED {
+		t.Errorf("recording = %+v, want it FAILED", r)
+	}
+
+	// FAILED is a default target.
+	if r, err := ov.WaitForRecordingWithOptions(context.Background(), rec.Id, opts); err != nil || r.Status != openvidu.FAILED {
+		t.Errorf("recording = %+v, %v, want it FAILED", r, err)
+	}
+}
+
+func TestWaitForRecordingCancel(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+	rec := stoppedRecording(t, srv, ov)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, done := startWait(ctx, ov, rec.Id, &openvidu.WaitOptions{Interval: time.Hour})
+	nextChange(t, changes)
+	cancel()
+
+	res := waitDone(t, done)
+	if !errors.Is(res.err, context.Canceled) || res.r != nil {
+		t.Errorf("recording = %+v, err = %v, want context.Canceled", res.r, res.err)
+	}
+}
+
+func TestWaitForRecordingWebhook(t *testing.T) {
+	srv := openvidutest.NewServer("secret")
+	defer srv.Close()
+	ov := srv.Client()
+	rec := stoppedRecording(t, srv, ov)
+
+	h := openvidu.NewWebhookHandler()
+	changes, done := startWait(context.Background(), ov, rec.Id, &openvidu.WaitOptions{Interval: time.Hour, Webhook: h})
+	nextChange(t, changes)
+
+	if err := srv.SetRecordingStatus(rec.Id, openvidu.READY); err != nil {
+		t.Fatal(err)
+	}
+	post := func(recordingId string) {
+		body := `{"event":"recordingStatusChanged","sessionId":"` + rec.SessionId + `","timestamp":1,"id":"` + recordingId + `","status":"ready"}`
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+	}
+
+	// Events for other recordings do not trigger a poll.
+	post("other")
+	select {
+	case c := <-changes:
+		t.Fatalf("polled after an event for another recording: %+v", c)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	post(rec.Id)
+	res := waitDone(t, done)
+	if res.err != nil || res.r.Status != openvidu.READY {
+		t.Errorf("recording = %+v, %v, want it READY", res.r, res.err)
+	}
+}
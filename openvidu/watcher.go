@@ -0,0 +1,91 @@
+package openvidu
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// WatcherOptions configures OpenVidu.Watch.
+type WatcherOptions struct {
+	// Interval is the delay between two fetches of the active sessions. It
+	// defaults to 10 seconds.
+	Interval time.Duration
+
+	// Jitter is the fraction, between 0 and 1, by which each delay is
+	// randomly lengthened or shortened, so that many watchers started
+	// together do not poll the server in lockstep.
+	Jitter float64
+
+	// MaxBackoff caps the delay between fetches after consecutive failures,
+	// which doubles with every failure starting at Interval. Failures do not
+	// lengthen the delay when MaxBackoff is not above Interval.
+	MaxBackoff time.Duration
+
+	// OnError, if set, is called with every failed fetch.
+	OnError func(error)
+}
+
+// DefaultWatcherOptions polls every 10 seconds, backing off up to 2 minutes
+// on errors.
+func DefaultWatcherOptions() *WatcherOptions {
+	return &WatcherOptions{
+		Interval:   10 * time.Second,
+		Jitter:     0.1,
+		MaxBackoff: 2 * time.Minute,
+	}
+}
+
+// Watch fetches the active sessions from the server right away and then
+// periodically, applying the changes to the client and publishing them to
+// the subscribers registered with Subscribe. It blocks until ctx is done
+// and then returns ctx.Err(). A nil opts uses DefaultWatcherOptions.
+func (o *OpenVidu) Watch(ctx context.Context, opts *WatcherOptions) error {
+	if opts == nil {
+		opts = DefaultWatcherOptions()
+	}
+	if opts.Interval <= 0 {
+		wo := *opts
+		wo.Interval = DefaultWatcherOptions().Interval
+		opts = &wo
+	}
+
+	failures := 0
+	for {
+		_, err := o.FetchChangesContext(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			failures++
+			if opts.OnError != nil {
+				opts.OnError(err)
+			}
+		} else {
+			failures = 0
+		}
+
+		if err := sleepContext(ctx, opts.delay(failures)); err != nil {
+			return err
+		}
+	}
+}
+
+func (wo *WatcherOptions) delay(failures int) time.Duration {
+	d := wo.Interval
+	for i := 0; i < failures && d < wo.MaxBackoff; i++ {
+		d *= 2
+		if d > wo.MaxBackoff {
+			d = wo.MaxBackoff
+		}
+	}
+
+	if wo.Jitter > 0 {
+		jitter := wo.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		d += time.Duration(float64(d) * jitter * (2*rand.Float64() - 1))
+	}
+	return d
+}
@@ -0,0 +1,144 @@
+package openvidu
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// timedTransport records when every request is sent to the wrapped
+// transport, and signals calls after each of them.
+type timedTransport struct {
+	http.RoundTripper
+	mu    sync.Mutex
+	times []time.Time
+	calls chan int
+}
+
+func (t *timedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.times = append(t.times, time.Now())
+	n := len(t.times)
+	t.mu.Unlock()
+	select {
+	case t.calls <- n:
+	default:
+	}
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func TestWatcherDelay(t *testing.T) {
+	wo := &WatcherOptions{Interval: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	for failures, want := range []time.Duration{10, 20, 40, 50, 50} {
+		if d := wo.delay(failures); d != want*time.Millisecond {
+			t.Errorf("delay(%d) = %v, want %v", failures, d, want*time.Millisecond)
+		}
+	}
+
+	wo.MaxBackoff = 0
+	if d := wo.delay(3); d != wo.Interval {
+		t.Errorf("delay without MaxBackoff = %v, want %v", d, wo.Interval)
+	}
+
+	wo.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := wo.delay(0); d < 5*time.Millisecond || d > 15*time.Millisecond {
+			t.Fatalf("delay with jitter = %v, want within 5ms and 15ms", d)
+		}
+	}
+}
+
+func TestWatchFetchesImmediately(t *testing.T) {
+	tr := &timedTransport{
+		RoundTripper: &flakyTransport{body: `{"numberOfElements":0,"content":[]}`},
+		calls:        make(chan int, 1),
+	}
+	ov := NewOpenVidu("https://localhost", "secret", WithTransport(tr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ov.Watch(ctx, &WatcherOptions{Interval: time.Hour})
+	}()
+
+	select {
+	case <-tr.calls:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no fetch before the first interval")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not return after cancellation")
+	}
+}
+
+func TestWatchBackoff(t *testing.T) {
+	const failures = 4
+	tr := &timedTransport{
+		RoundTripper: &flakyTransport{failures: failures, body: `{"numberOfElements":0,"content":[]}`},
+		calls:        make(chan int, 10),
+	}
+	ov := NewOpenVidu("https://localhost", "secret", WithTransport(tr))
+
+	var mu sync.Mutex
+	var errs []error
+	opts := &WatcherOptions{
+		Interval:   2 * time.Millisecond,
+		MaxBackoff: time.Second,
+		OnError: func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ov.Watch(ctx, opts)
+	}()
+	for n := 0; n < failures+2; {
+		select {
+		case n = <-tr.calls:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%d fetches, want %d", n, failures+2)
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != failures {
+		t.Fatalf("OnError called %d times, want %d", len(errs), failures)
+	}
+	for _, err := range errs {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("OnError got %v, want a 503 APIError", err)
+		}
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	want := opts.Interval
+	for i := 0; i < failures; i++ {
+		want *= 2
+		if gap := tr.times[i+1].Sub(tr.times[i]); gap < want {
+			t.Errorf("delay after failure %d = %v, want at least %v", i+1, gap, want)
+		}
+	}
+	if after, before := tr.times[failures+1].Sub(tr.times[failures]), tr.times[failures].Sub(tr.times[failures-1]); after >= before {
+		t.Errorf("delay after a success = %v, want it reset below %v", after, before)
+	}
+}
@@ -0,0 +1,381 @@
+package openvidu
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+type WebhookEventType string
+
+const (
+	SESSION_CREATED             WebhookEventType = "sessionCreated"
+	SESSION_DESTROYED           WebhookEventType = "sessionDestroyed"
+	PARTICIPANT_JOINED          WebhookEventType = "participantJoined"
+	PARTICIPANT_LEFT            WebhookEventType = "participantLeft"
+	WEBRTC_CONNECTION_CREATED   WebhookEventType = "webrtcConnectionCreated"
+	WEBRTC_CONNECTION_DESTROYED WebhookEventType = "webrtcConnectionDestroyed"
+	RECORDING_STATUS_CHANGED    WebhookEventType = "recordingStatusChanged"
+	FILTER_EVENT_DISPATCHED     WebhookEventType = "filterEventDispatched"
+	SIGNAL_SENT                 WebhookEventType = "signalSent"
+)
+
+type WebrtcConnectionType string
+
+const (
+	INBOUND  WebrtcConnectionType = "INBOUND"
+	OUTBOUND WebrtcConnectionType = "OUTBOUND"
+)
+
+// WebhookEvent is implemented by every event decoded by WebhookHandler. Use
+// a type switch to get to the fields of a specific event.
+type WebhookEvent interface {
+	EventType() WebhookEventType
+	EventSessionId() string
+	EventTimestamp() int64
+}
+
+// EventHeader holds the fields common to all webhook events.
+type EventHeader struct {
+	Event     WebhookEventType `json:"event"`
+	SessionId string           `json:"sessionId"`
+	Timestamp int64            `json:"timestamp"`
+}
+
+func (h *EventHeader) EventType() WebhookEventType {
+	return h.Event
+}
+
+func (h *EventHeader) EventSessionId() string {
+	return h.SessionId
+}
+
+func (h *EventHeader) EventTimestamp() int64 {
+	return h.Timestamp
+}
+
+type SessionCreatedEvent struct {
+	EventHeader
+}
+
+type SessionDestroyedEvent struct {
+	EventHeader
+	StartTime int64  `json:"startTime"`
+	Duration  int64  `json:"duration"`
+	Reason    string `json:"reason"`
+}
+
+// ParticipantJoinedEvent is sent when a participant connects to a session.
+// OpenVidu 2.16 renamed participantId to connectionId in this and the other
+// connection events; ParseWebhookEvent sets both fields either way.
+type ParticipantJoinedEvent struct {
+	EventHeader
+	ParticipantId string `json:"participantId"`
+	ConnectionId  string `json:"connectionId"`
+	Location      string `json:"location"`
+	Platform      string `json:"platform"`
+	ClientData    string `json:"clientData"`
+	ServerData    string `json:"serverData"`
+}
+
+type ParticipantLeftEvent struct {
+	EventHeader
+	ParticipantId string `json:"participantId"`
+	ConnectionId  string `json:"connectionId"`
+	Location      string `json:"location"`
+	Platform      string `json:"platform"`
+	ClientData    string `json:"clientData"`
+	ServerData    string `json:"serverData"`
+	StartTime     int64  `json:"startTime"`
+	Duration      int64  `json:"duration"`
+	Reason        string `json:"reason"`
+}
+
+// WebrtcConnectionCreatedEvent is sent when a participant starts publishing
+// (OUTBOUND) or subscribes to the stream of another participant (INBOUND),
+// in which case ReceivingFrom is the connection id of the publisher.
+type WebrtcConnectionCreatedEvent struct {
+	EventHeader
+	ParticipantId   string               `json:"participantId"`
+	ConnectionId    string               `json:"connectionId"`
+	Connection      WebrtcConnectionType `json:"connection"`
+	ReceivingFrom   string               `json:"receivingFrom"`
+	StreamId        string               `json:"streamId"`
+	AudioEnabled    bool                 `json:"audioEnabled"`
+	VideoEnabled    bool                 `json:"videoEnabled"`
+	VideoSource     string               `json:"videoSource"`
+	VideoFramerate  int32                `json:"videoFramerate"`
+	VideoDimensions string               `json:"videoDimensions"`
+}
+
+type WebrtcConnectionDestroyedEvent struct {
+	EventHeader
+	ParticipantId   string               `json:"participantId"`
+	ConnectionId    string               `json:"connectionId"`
+	Connection      WebrtcConnectionType `json:"connection"`
+	ReceivingFrom   string               `json:"receivingFrom"`
+	StreamId        string               `json:"streamId"`
+	AudioEnabled    bool                 `json:"audioEnabled"`
+	VideoEnabled    bool                 `json:"videoEnabled"`
+	VideoSource     string               `json:"videoSource"`
+	VideoFramerate  int32                `json:"videoFramerate"`
+	VideoDimensions string               `json:"videoDimensions"`
+	StartTime       int64                `json:"startTime"`
+	Duration        int64                `json:"duration"`
+	Reason          string               `json:"reason"`
+}
+
+type RecordingStatusChangedEvent struct {
+	EventHeader
+	Id              string          `json:"id"`
+	Name            string          `json:"name"`
+	Status          RecordingStatus `json:"status"`
+	OutputMode      OutputMode      `json:"outputMode"`
+	RecordingLayout RecordingLayout `json:"recordingLayout"`
+	Resolution      string          `json:"resolution"`
+	HasAudio        bool            `json:"hasAudio"`
+	HasVideo        bool            `json:"hasVideo"`
+	StartTime       int64           `json:"startTime"`
+	Size            int64           `json:"size"`
+	Duration        float64         `json:"duration"`
+	Reason          string          `json:"reason"`
+}
+
+type FilterEventDispatchedEvent struct {
+	EventHeader
+	ParticipantId string          `json:"participantId"`
+	ConnectionId  string          `json:"connectionId"`
+	StreamId      string          `json:"streamId"`
+	FilterType    string          `json:"filterType"`
+	FilterEvent   string          `json:"eventType"`
+	Data          json.RawMessage `json:"data"`
+}
+
+type SignalSentEvent struct {
+	EventHeader
+	From string   `json:"from"`
+	Type string   `json:"type"`
+	Data string   `json:"data"`
+	To   []string `json:"to"`
+}
+
+// UnknownEvent is delivered for event types this client does not know. Raw
+// holds the complete request body.
+type UnknownEvent struct {
+	EventHeader
+	Raw json.RawMessage `json:"-"`
+}
+
+// ParseWebhookEvent decodes the body of an OpenVidu webhook request into its
+// typed event.
+func ParseWebhookEvent(body []byte) (WebhookEvent, error) {
+	var header EventHeader
+	if err := json.Unmarshal(body, &header); err != nil {
+		return nil, err
+	}
+
+	var event WebhookEvent
+	switch header.Event {
+	case SESSION_CREATED:
+		event = &SessionCreatedEvent{}
+	case SESSION_DESTROYED:
+		event = &SessionDestroyedEvent{}
+	case PARTICIPANT_JOINED:
+		event = &ParticipantJoinedEvent{}
+	case PARTICIPANT_LEFT:
+		event = &ParticipantLeftEvent{}
+	case WEBRTC_CONNECTION_CREATED:
+		event = &WebrtcConnectionCreatedEvent{}
+	case WEBRTC_CONNECTION_DESTROYED:
+		event = &WebrtcConnectionDestroyedEvent{}
+	case RECORDING_STATUS_CHANGED:
+		event = &RecordingStatusChangedEvent{}
+	case FILTER_EVENT_DISPATCHED:
+		event = &FilterEventDispatchedEvent{}
+	case SIGNAL_SENT:
+		event = &SignalSentEvent{}
+	default:
+		return &UnknownEvent{EventHeader: header, Raw: json.RawMessage(body)}, nil
+	}
+
+	if err := json.Unmarshal(body, event); err != nil {
+		return nil, err
+	}
+
+	switch e := event.(type) {
+	case *ParticipantJoinedEvent:
+		fillConnectionId(&e.ConnectionId, &e.ParticipantId)
+	case *ParticipantLeftEvent:
+		fillConnectionId(&e.ConnectionId, &e.ParticipantId)
+	case *WebrtcConnectionCreatedEvent:
+		fillConnectionId(&e.ConnectionId, &e.ParticipantId)
+	case *WebrtcConnectionDestroyedEvent:
+		fillConnectionId(&e.ConnectionId, &e.ParticipantId)
+	case *FilterEventDispatchedEvent:
+		fillConnectionId(&e.ConnectionId, &e.ParticipantId)
+	}
+	return event, nil
+}
+
+// fillConnectionId sets whichever of connectionId and participantId is
+// missing from the other: OpenVidu 2.16 renamed participantId to
+// connectionId.
+func fillConnectionId(connectionId *string, participantId *string) {
+	*connectionId = eventConnectionId(*connectionId, *participantId)
+	*participantId = *connectionId
+}
+
+func eventConnectionId(connectionId string, participantId string) string {
+	if len(connectionId) > 0 {
+		return connectionId
+	}
+	return participantId
+}
+
+const maxWebhookBodySize = 1 << 20
+
+// WebhookHandler is an http.Handler receiving the events OpenVidu posts to
+// its configured webhook endpoint. Every decoded event is passed to the
+// callbacks registered with On and OnAny, then sent to the channels
+// registered with Notify.
+type WebhookHandler struct {
+	mu          sync.RWMutex
+	headerName  string
+	headerValue string
+	callbacks   map[WebhookEventType][]func(WebhookEvent)
+	anyCallback []func(WebhookEvent)
+	channels    []chan<- WebhookEvent
+	nextId      int
+	subscribers map[int]func(WebhookEvent)
+}
+
+func NewWebhookHandler() *WebhookHandler {
+	return &WebhookHandler{
+		callbacks: make(map[WebhookEventType][]func(WebhookEvent)),
+	}
+}
+
+// RequireHeader makes the handler reject requests not carrying the header
+// name with the given value, as configured with OPENVIDU_WEBHOOK_HEADERS.
+func (h *WebhookHandler) RequireHeader(name string, value string) *WebhookHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.headerName = name
+	h.headerValue = value
+	return h
+}
+
+// RequireBasicAuth makes the handler reject requests not authenticated with
+// the OpenVidu secret, i.e. without the header
+// "Authorization: Basic <base64 of OPENVIDUAPP:secret>".
+func (h *WebhookHandler) RequireBasicAuth(secret string) *WebhookHandler {
+	return h.RequireHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("OPENVIDUAPP:"+secret)))
+}
+
+// On registers fn to be called with every event of type t.
+func (h *WebhookHandler) On(t WebhookEventType, fn func(WebhookEvent)) *WebhookHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.callbacks == nil {
+		h.callbacks = make(map[WebhookEventType][]func(WebhookEvent))
+	}
+	h.callbacks[t] = append(h.callbacks[t], fn)
+	return h
+}
+
+// OnAny registers fn to be called with every event.
+func (h *WebhookHandler) OnAny(fn func(WebhookEvent)) *WebhookHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.anyCallback = append(h.anyCallback, fn)
+	return h
+}
+
+// subscribe registers fn to be called with every event until the returned
+// function is called.
+func (h *WebhookHandler) subscribe(fn func(WebhookEvent)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers == nil {
+		h.subscribers = make(map[int]func(WebhookEvent))
+	}
+	id := h.nextId
+	h.nextId++
+	h.subscribers[id] = fn
+
+	return func() {
+		h.mu.Lock()
+		delete(h.subscribers, id)
+		h.mu.Unlock()
+	}
+}
+
+// Notify makes the handler send every event to ch. The webhook request is
+// not answered until the event has been received, so ch should be buffered
+// or drained promptly.
+func (h *WebhookHandler) Notify(ch chan<- WebhookEvent) *WebhookHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.channels = append(h.channels, ch)
+	return h
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorized(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	event, err := ParseWebhookEvent(body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	callbacks := append(h.callbacks[event.EventType()][:0:0], h.callbacks[event.EventType()]...)
+	callbacks = append(callbacks, h.anyCallback...)
+	for _, fn := range h.subscribers {
+		callbacks = append(callbacks, fn)
+	}
+	channels := append(h.channels[:0:0], h.channels...)
+	h.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		fn(event)
+	}
+	for _, ch := range channels {
+		select {
+		case ch <- event:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) authorized(r *http.Request) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.headerName) == 0 {
+		return true
+	}
+	got := r.Header.Get(h.headerName)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(h.headerValue)) == 1
+}
@@ -0,0 +1,236 @@
+package openvidu_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/anidotnet/openvidu-go-client/openvidu"
+)
+
+func TestParseWebhookEvent(t *testing.T) {
+	header := openvidu.EventHeader{SessionId: "ses_1", Timestamp: 1}
+	withEvent := func(e openvidu.WebhookEventType) openvidu.EventHeader {
+		h := header
+		h.Event = e
+		return h
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want openvidu.WebhookEvent
+	}{
+		{
+			"sessionCreated",
+			`{"event":"sessionCreated","sessionId":"ses_1","timestamp":1}`,
+			&openvidu.SessionCreatedEvent{EventHeader: withEvent(openvidu.SESSION_CREATED)},
+		},
+		{
+			"sessionDestroyed",
+			`{"event":"sessionDestroyed","sessionId":"ses_1","timestamp":1,"startTime":0,"duration":1,"reason":"lastParticipantLeft"}`,
+			&openvidu.SessionDestroyedEvent{EventHeader: withEvent(openvidu.SESSION_DESTROYED), Duration: 1, Reason: "lastParticipantLeft"},
+		},
+		{
+			"participantJoined",
+			`{"event":"participantJoined","sessionId":"ses_1","timestamp":1,"connectionId":"con_1","location":"here","platform":"Chrome","clientData":"c","serverData":"s"}`,
+			&openvidu.ParticipantJoinedEvent{
+				EventHeader:   withEvent(openvidu.PARTICIPANT_JOINED),
+				ParticipantId: "con_1",
+				ConnectionId:  "con_1",
+				Location:      "here",
+				Platform:      "Chrome",
+				ClientData:    "c",
+				ServerData:    "s",
+			},
+		},
+		{
+			"participantJoined before 2.16",
+			`{"event":"participantJoined","sessionId":"ses_1","timestamp":1,"participantId":"con_1"}`,
+			&openvidu.ParticipantJoinedEvent{EventHeader: withEvent(openvidu.PARTICIPANT_JOINED), ParticipantId: "con_1", ConnectionId: "con_1"},
+		},
+		{
+			"participantLeft",
+			`{"event":"participantLeft","sessionId":"ses_1","timestamp":1,"connectionId":"con_1","startTime":0,"duration":1,"reason":"disconnect"}`,
+			&openvidu.ParticipantLeftEvent{
+				EventHeader:   withEvent(openvidu.PARTICIPANT_LEFT),
+				ParticipantId: "con_1",
+				ConnectionId:  "con_1",
+				Duration:      1,
+				Reason:        "disconnect",
+			},
+		},
+		{
+			"webrtcConnectionCreated",
+			`{"event":"webrtcConnectionCreated","sessionId":"ses_1","timestamp":1,"connectionId":"con_1","connection":"INBOUND","receivingFrom":"con_2","streamId":"str_2","audioEnabled":true,"videoEnabled":true,"videoSource":"CAMERA","videoFramerate":30,"videoDimensions":"{\"width\":640,\"height\":480}"}`,
+			&openvidu.WebrtcConnectionCreatedEvent{
+				EventHeader:     withEvent(openvidu.WEBRTC_CONNECTION_CREATED),
+				ParticipantId:   "con_1",
+				ConnectionId:    "con_1",
+				Connection:      openvidu.INBOUND,
+				ReceivingFrom:   "con_2",
+				StreamId:        "str_2",
+				AudioEnabled:    true,
+				VideoEnabled:    true,
+				VideoSource:     "CAMERA",
+				VideoFramerate:  30,
+				VideoDimensions: `{"width":640,"height":480}`,
+			},
+		},
+		{
+			"webrtcConnectionDestroyed",
+			`{"event":"webrtcConnectionDestroyed","sessionId":"ses_1","timestamp":1,"participantId":"con_1","connection":"OUTBOUND","streamId":"str_1","reason":"unpublish"}`,
+			&openvidu.WebrtcConnectionDestroyedEvent{
+				EventHeader:   withEvent(openvidu.WEBRTC_CONNECTION_DESTROYED),
+				ParticipantId: "con_1",
+				ConnectionId:  "con_1",
+				Connection:    openvidu.OUTBOUND,
+				StreamId:      "str_1",
+				Reason:        "unpublish",
+			},
+		},
+		{
+			"recordingStatusChanged",
+			`{"event":"recordingStatusChanged","sessionId":"ses_1","timestamp":1,"id":"ses_1","name":"ses_1","status":"ready","outputMode":"COMPOSED","recordingLayout":"BEST_FIT","resolution":"1280x720","hasAudio":true,"hasVideo":true,"size":10,"duration":1.5,"reason":"recordingStoppedByServer"}`,
+			&openvidu.RecordingStatusChangedEvent{
+				EventHeader:     withEvent(openvidu.RECORDING_STATUS_CHANGED),
+				Id:              "ses_1",
+				Name:            "ses_1",
+				Status:          openvidu.READY,
+				OutputMode:      openvidu.COMPOSED,
+				RecordingLayout: openvidu.BEST_FIT,
+				Resolution:      "1280x720",
+				HasAudio:        true,
+				HasVideo:        true,
+				Size:            10,
+				Duration:        1.5,
+				Reason:          "recordingStoppedByServer",
+			},
+		},
+		{
+			"filterEventDispatched",
+			`{"event":"filterEventDispatched","sessionId":"ses_1","timestamp":1,"connectionId":"con_1","streamId":"str_1","filterType":"ZBarFilter","eventType":"CodeFound","data":{"codeType":"QR-Code"}}`,
+			&openvidu.FilterEventDispatchedEvent{
+				EventHeader:   withEvent(openvidu.FILTER_EVENT_DISPATCHED),
+				ParticipantId: "con_1",
+				ConnectionId:  "con_1",
+				StreamId:      "str_1",
+				FilterType:    "ZBarFilter",
+				FilterEvent:   "CodeFound",
+				Data:          []byte(`{"codeType":"QR-Code"}`),
+			},
+		},
+		{
+			"signalSent",
+			`{"event":"signalSent","sessionId":"ses_1","timestamp":1,"from":"con_1","type":"chat","data":"hello","to":["con_2"]}`,
+			&openvidu.SignalSentEvent{EventHeader: withEvent(openvidu.SIGNAL_SENT), From: "con_1", Type: "chat", Data: "hello", To: []string{"con_2"}},
+		},
+		{
+			"unknown",
+			`{"event":"nodeCrashed","sessionId":"ses_1","timestamp":1,"id":"node_1"}`,
+			&openvidu.UnknownEvent{
+				EventHeader: withEvent("nodeCrashed"),
+				Raw:         []byte(`{"event":"nodeCrashed","sessionId":"ses_1","timestamp":1,"id":"node_1"}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := openvidu.ParseWebhookEvent([]byte(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(event, tt.want) {
+				t.Errorf("event = %+v, want %+v", event, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWebhookEventInvalid(t *testing.T) {
+	for _, body := range []string{``, `{"event":`, `{"event":"participantJoined","timestamp":"now"}`} {
+		if event, err := openvidu.ParseWebhookEvent([]byte(body)); err == nil {
+			t.Errorf("ParseWebhookEvent(%q) = %+v, want an error", body, event)
+		}
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	var h openvidu.WebhookHandler
+	var joined, all []openvidu.WebhookEvent
+	h.On(openvidu.PARTICIPANT_JOINED, func(e openvidu.WebhookEvent) {
+		joined = append(joined, e)
+	})
+	h.OnAny(func(e openvidu.WebhookEvent) {
+		all = append(all, e)
+	})
+	ch := make(chan openvidu.WebhookEvent, 2)
+	h.Notify(ch)
+
+	for _, body := range []string{
+		`{"event":"sessionCreated","sessionId":"ses_1","timestamp":1}`,
+		`{"event":"participantJoined","sessionId":"ses_1","timestamp":2,"connectionId":"con_1"}`,
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+	}
+
+	if len(joined) != 1 || joined[0].(*openvidu.ParticipantJoinedEvent).ConnectionId != "con_1" {
+		t.Errorf("participantJoined callback got %+v", joined)
+	}
+	if len(all) != 2 || len(ch) != 2 {
+		t.Errorf("OnAny got %d events and the channel %d, want 2", len(all), len(ch))
+	}
+}
+
+func TestWebhookHandlerStatus(t *testing.T) {
+	body := `{"event":"sessionCreated","sessionId":"ses_1","timestamp":1}`
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("OPENVIDUAPP:secret"))
+
+	tests := []struct {
+		name    string
+		handler *openvidu.WebhookHandler
+		method  string
+		header  http.Header
+		body    string
+		want    int
+	}{
+		{"no auth", openvidu.NewWebhookHandler(), http.MethodPost, nil, body, http.StatusOK},
+		{"get", openvidu.NewWebhookHandler(), http.MethodGet, nil, "", http.StatusMethodNotAllowed},
+		{"bad body", openvidu.NewWebhookHandler(), http.MethodPost, nil, "{", http.StatusBadRequest},
+		{"header", openvidu.NewWebhookHandler().RequireHeader("X-Token", "t"), http.MethodPost, http.Header{"X-Token": {"t"}}, body, http.StatusOK},
+		{"missing header", openvidu.NewWebhookHandler().RequireHeader("X-Token", "t"), http.MethodPost, nil, body, http.StatusUnauthorized},
+		{"wrong header", openvidu.NewWebhookHandler().RequireHeader("X-Token", "t"), http.MethodPost, http.Header{"X-Token": {"u"}}, body, http.StatusUnauthorized},
+		{"basic auth", openvidu.NewWebhookHandler().RequireBasicAuth("secret"), http.MethodPost, http.Header{"Authorization": {basic}}, body, http.StatusOK},
+		{"missing basic auth", openvidu.NewWebhookHandler().RequireBasicAuth("secret"), http.MethodPost, nil, body, http.StatusUnauthorized},
+		{"wrong secret", openvidu.NewWebhookHandler().RequireBasicAuth("other"), http.MethodPost, http.Header{"Authorization": {basic}}, body, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			tt.handler.OnAny(func(openvidu.WebhookEvent) {
+				called = true
+			})
+
+			r := httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body))
+			for name, values := range tt.header {
+				r.Header[name] = values
+			}
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if called != (tt.want == http.StatusOK) {
+				t.Errorf("callback called = %v with status %d", called, w.Code)
+			}
+		})
+	}
+}